@@ -71,15 +71,19 @@ func (p *Program) TokenLiteral() string {
 }
 
 /*
-	let 文を表す構造体型.（ex. let <identifier> = <expression>;）
+	let 文を表す構造体型.（ex. let <identifier> = <expression>; / let <pattern> = <expression>;）
 	Toke 	: let 文を示すトークン
-	Name	: 識別子の名前
+	Name	: 識別子の名前（分割代入の場合は nil）
+	Pattern	: 分割代入のパターン（ex. let [a, b] = xs; の [a, b]）. 識別子への束縛の場合は nil
+	Type	: 型注釈（ex. let x: int = 5; の int）. 省略された場合は nil
 	Value	: 値を生成する式
  */
 type LetStatement struct {
-	Token token.Token // token.LET トークン
-	Name  *Identifier
-	Value Expression
+	Token   token.Token // token.LET トークン
+	Name    *Identifier
+	Pattern Pattern
+	Type    TypeAnnotation
+	Value   Expression
 }
 
 /*
@@ -91,7 +95,14 @@ func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String())
+	if ls.Pattern != nil {
+		out.WriteString(ls.Pattern.String())
+	} else {
+		out.WriteString(ls.Name.String())
+	}
+	if ls.Type != nil {
+		out.WriteString(": " + ls.Type.String())
+	}
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -102,6 +113,36 @@ func (ls *LetStatement) String() string {
 	return out.String()
 }
 
+/*
+	const 文を表す構造体型.（ex. const <identifier> = <expression>;）
+	const 文で束縛した名前は, 同じスコープで束縛し直せない.
+	Token	: token.CONST トークン
+	Name	: 識別子の名前
+	Value	: 値を生成する式
+ */
+type ConstStatement struct {
+	Token token.Token // token.CONST トークン
+	Name  *Identifier
+	Value Expression
+}
+
+func (cs *ConstStatement) statementNode()       {}
+func (cs *ConstStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ConstStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(cs.TokenLiteral() + " ")
+	out.WriteString(cs.Name.String())
+	out.WriteString(" = ")
+
+	if cs.Value != nil {
+		out.WriteString(cs.Value.String())
+	}
+
+	out.WriteString(";")
+	return out.String()
+}
+
 /*
 	識別子を表す構造体型.
 	Token 	: 識別子を示すトークン
@@ -118,6 +159,7 @@ type Identifier struct {
 	ノードの種類を少なく保ち, 式として識別子を表現する.
  */
 func (i *Identifier) expressionNode()      {}
+func (i *Identifier) patternNode()         {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
 func (i *Identifier) String() string       { return i.Value }
 
@@ -139,10 +181,10 @@ func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(rs.TokenLiteral() + " ")
+	out.WriteString(rs.TokenLiteral())
 
 	if rs.ReturnValue != nil {
-		out.WriteString(rs.ReturnValue.String())
+		out.WriteString(" " + rs.ReturnValue.String())
 	}
 
 	out.WriteString(";")
@@ -305,9 +347,28 @@ func (bs *BlockStatement) String() string {
 	return out.String()
 }
 
+/*
+	関数リテラルを表す構造体型.（ex. fn(x, y = 10, ...rest) { <block statement> }）
+	メソッド定義（ex. fn (p Point) norm() { <block statement> }）の場合は Receiver と Name を持つ.
+	Token		: 'fn' トークン
+	Receiver	: メソッドのレシーバ. 通常の関数リテラルでは nil
+	Name		: メソッドの名前. 通常の関数リテラルでは nil
+	Parameters	: 仮引数の識別子（残余引数は含まない）
+	Defaults	: 仮引数名からデフォルト値の式へのマップ（デフォルト値を持つ仮引数のみ）
+	Types		: 仮引数名から型注釈へのマップ（型注釈を持つ仮引数のみ）
+	Rest		: 残余引数（ex. ...rest）. なければ nil
+	ReturnType	: 戻り値の型注釈（ex. fn(x: int) -> int の int）. 省略された場合は nil
+	Body		: 関数本体
+ */
 type FunctionLiteral struct {
 	Token      token.Token // 'fn' トークン
+	Receiver   *Receiver
+	Name       *Identifier
 	Parameters []*Identifier
+	Defaults   map[string]Expression
+	Types      map[string]TypeAnnotation
+	Rest       *Identifier
+	ReturnType TypeAnnotation
 	Body       *BlockStatement
 }
 
@@ -318,22 +379,47 @@ func (fl *FunctionLiteral) String() string {
 
 	params := []string{}
 	for _, p := range fl.Parameters {
-		params = append(params, p.String())
+		param := p.String()
+		if typ, ok := fl.Types[p.Value]; ok {
+			param += ": " + typ.String()
+		}
+		if def, ok := fl.Defaults[p.Value]; ok {
+			param += " = " + def.String()
+		}
+		params = append(params, param)
+	}
+	if fl.Rest != nil {
+		params = append(params, "..."+fl.Rest.String())
 	}
 
 	out.WriteString(fl.TokenLiteral())
+	if fl.Receiver != nil {
+		out.WriteString(" (" + fl.Receiver.String() + ") ")
+		out.WriteString(fl.Name.String())
+	}
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
+	if fl.ReturnType != nil {
+		out.WriteString("-> " + fl.ReturnType.String() + " ")
+	}
 	out.WriteString(fl.Body.String())
 
 	return out.String()
 }
 
+/*
+	関数呼び出し式を表す構造体型.（ex. add(1, ...xs, y: 2)）
+	Token			: '(' トークン
+	Function		: 呼び出される関数
+	Arguments		: 位置引数（SpreadExpression を含むことがある）
+	NamedArguments	: キーワード引数（位置引数の後ろにのみ置ける）
+ */
 type CallExpression struct {
-	Token     token.Token // '(' トークン
-	Function  Expression  // Identifier か FunctionLiteral
-	Arguments []Expression
+	Token          token.Token // '(' トークン
+	Function       Expression  // Identifier か FunctionLiteral
+	Arguments      []Expression
+	NamedArguments []*NamedArgument
 }
 
 func (ce *CallExpression) expressionNode()      {}
@@ -345,6 +431,9 @@ func (ce *CallExpression) String() string {
 	for _, a := range ce.Arguments {
 		args = append(args, a.String())
 	}
+	for _, na := range ce.NamedArguments {
+		args = append(args, na.String())
+	}
 
 	out.WriteString(ce.Function.String())
 	out.WriteString("(")
@@ -353,3 +442,728 @@ func (ce *CallExpression) String() string {
 
 	return out.String()
 }
+
+/*
+	呼び出し時に配列を展開して位置引数として渡す式の構造体型.（ex. f(...xs)）
+	Token	: '...' トークン
+	Value	: 展開される式
+ */
+type SpreadExpression struct {
+	Token token.Token // '...' トークン
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode()      {}
+func (se *SpreadExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpreadExpression) String() string       { return "..." + se.Value.String() }
+
+/*
+	キーワード引数を表す構造体型.（ex. f(y: 2) の y: 2）
+	Token	: 引数名の IDENT トークン
+	Name	: 引数名
+	Value	: 渡す値の式
+ */
+type NamedArgument struct {
+	Token token.Token // 引数名の IDENT トークン
+	Name  *Identifier
+	Value Expression
+}
+
+func (na *NamedArgument) TokenLiteral() string { return na.Token.Literal }
+func (na *NamedArgument) String() string {
+	return na.Name.String() + ": " + na.Value.String()
+}
+
+/*
+	束縛のパターンを表すノード.（ex. let [a, b] = xs; の [a, b]）
+	Identifier, ArrayPattern, HashPattern, WildcardPattern, LiteralPattern が実装する.
+	patternNode() : ダミーメソッド. コンパイルの段階で弾かせるため実装は持たなくて良い.
+ */
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+/*
+	配列の分割代入パターンを表す構造体型.（ex. [a, b, ...rest]）
+	Token		: '[' トークン
+	Elements	: 先頭から順に要素を束縛するパターン
+	Rest		: 残りの要素を束縛する識別子. なければ nil
+ */
+type ArrayPattern struct {
+	Token    token.Token // '[' トークン
+	Elements []Pattern
+	Rest     *Identifier
+}
+
+func (ap *ArrayPattern) patternNode()         {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) String() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, e := range ap.Elements {
+		elements = append(elements, e.String())
+	}
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+/*
+	ハッシュの分割代入パターンを表す構造体型.（ex. {name, age: years}）
+	Token	: '{' トークン
+	Entries	: キーと, そのキーの値を束縛するパターンの組
+ */
+type HashPattern struct {
+	Token   token.Token // '{' トークン
+	Entries []*HashPatternEntry
+}
+
+func (hp *HashPattern) patternNode()         {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) String() string {
+	var out bytes.Buffer
+
+	entries := []string{}
+	for _, e := range hp.Entries {
+		entries = append(entries, e.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(entries, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+/*
+	HashPattern の 1 つの要素を表す構造体型.（ex. age: years）
+	Key		: ハッシュのキー
+	Value	: キーの値を束縛するパターン（{name} のような省略形では Key と同じ名前の Identifier）
+ */
+type HashPatternEntry struct {
+	Key   *Identifier
+	Value Pattern
+}
+
+func (he *HashPatternEntry) TokenLiteral() string { return he.Key.TokenLiteral() }
+func (he *HashPatternEntry) String() string {
+	if ident, ok := he.Value.(*Identifier); ok && ident.Value == he.Key.Value {
+		return he.Key.String()
+	}
+	return he.Key.String() + ": " + he.Value.String()
+}
+
+/*
+	どんな値にもマッチし, 何も束縛しないパターンを表す構造体型.（ex. _）
+ */
+type WildcardPattern struct {
+	Token token.Token // '_' トークン
+}
+
+func (wp *WildcardPattern) patternNode()         {}
+func (wp *WildcardPattern) TokenLiteral() string { return wp.Token.Literal }
+func (wp *WildcardPattern) String() string       { return wp.Token.Literal }
+
+/*
+	リテラルと等しい値にだけマッチするパターンを表す構造体型.（ex. 1, -1, true）
+	Token	: リテラルの最初のトークン
+	Value	: IntegerLiteral か Boolean
+ */
+type LiteralPattern struct {
+	Token token.Token
+	Value Expression
+}
+
+func (lp *LiteralPattern) patternNode()         {}
+func (lp *LiteralPattern) TokenLiteral() string { return lp.Token.Literal }
+func (lp *LiteralPattern) String() string       { return lp.Value.String() }
+
+/*
+	match 式を表す構造体型.（ex. match (<expression>) { <pattern> => <expression>, ... }）
+	Token	: 'match' トークン
+	Subject	: マッチさせる値を生成する式
+	Arms	: 上から順に試される分岐
+ */
+type MatchExpression struct {
+	Token   token.Token // 'match' トークン
+	Subject Expression
+	Arms    []*MatchArm
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+
+	arms := []string{}
+	for _, a := range me.Arms {
+		arms = append(arms, a.String())
+	}
+
+	out.WriteString("match (")
+	out.WriteString(me.Subject.String())
+	out.WriteString(") { ")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+/*
+	match 式の 1 つの分岐を表す構造体型.（ex. [x, y] if x < y => x）
+	Token	: パターンの最初のトークン
+	Pattern	: Subject の値と照合するパターン
+	Guard	: パターンにマッチした後に評価する条件式. なければ nil
+	Body	: 分岐が選ばれた時に評価する式
+ */
+type MatchArm struct {
+	Token   token.Token
+	Pattern Pattern
+	Guard   Expression
+	Body    Expression
+}
+
+func (ma *MatchArm) TokenLiteral() string { return ma.Token.Literal }
+func (ma *MatchArm) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ma.Pattern.String())
+	if ma.Guard != nil {
+		out.WriteString(" if ")
+		out.WriteString(ma.Guard.String())
+	}
+	out.WriteString(" => ")
+	out.WriteString(ma.Body.String())
+
+	return out.String()
+}
+
+/*
+	パイプライン式を表す構造体型.（ex. xs |> filter(f) |> sum）
+	Token	: '|>' トークン
+	Left	: 関数に渡す値を生成する式
+	Right	: 呼び出される関数. CallExpression なら Left はその第 1 引数として渡される
+ */
+type PipeExpression struct {
+	Token token.Token // '|>' トークン
+	Left  Expression
+	Right Expression
+}
+
+func (pe *PipeExpression) expressionNode()      {}
+func (pe *PipeExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PipeExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(pe.Left.String())
+	out.WriteString(" |> ")
+	out.WriteString(pe.Right.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+/*
+	パイプライン式を, 同じ意味の関数呼び出し式に変換して返すメソッド.
+	x |> f は f(x) に, x |> f(a) は f(x, a) になる.
+ */
+func (pe *PipeExpression) Call() *CallExpression {
+	if call, ok := pe.Right.(*CallExpression); ok {
+		args := append([]Expression{pe.Left}, call.Arguments...)
+		return &CallExpression{
+			Token:          call.Token,
+			Function:       call.Function,
+			Arguments:      args,
+			NamedArguments: call.NamedArguments,
+		}
+	}
+
+	return &CallExpression{
+		Token:     token.Token{Type: token.LPAREN, Literal: "("},
+		Function:  pe.Right,
+		Arguments: []Expression{pe.Left},
+	}
+}
+
+/*
+	spawn 式を表す構造体型.（ex. spawn worker(c, x)）
+	関数呼び出しを, 呼び出し元とは別の goroutine で実行する.
+	Token	: 'spawn' トークン
+	Call	: 別の goroutine で実行する関数呼び出し
+ */
+type SpawnExpression struct {
+	Token token.Token // 'spawn' トークン
+	Call  *CallExpression
+}
+
+func (se *SpawnExpression) expressionNode()      {}
+func (se *SpawnExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpawnExpression) String() string {
+	return se.TokenLiteral() + " " + se.Call.String()
+}
+
+/*
+	select 式を表す構造体型.（ex. select { recv(c) as v => v, send(d, 1) => 0, _ => -1 }）
+	複数のチャネル操作のうち, 最初に実行できたものの分岐を評価する.
+	Token	: 'select' トークン
+	Cases	: 分岐
+ */
+type SelectExpression struct {
+	Token token.Token // 'select' トークン
+	Cases []*SelectCase
+}
+
+func (se *SelectExpression) expressionNode()      {}
+func (se *SelectExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SelectExpression) String() string {
+	var out bytes.Buffer
+
+	cases := []string{}
+	for _, c := range se.Cases {
+		cases = append(cases, c.String())
+	}
+
+	out.WriteString("select { ")
+	out.WriteString(strings.Join(cases, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+/*
+	select 式の 1 つの分岐を表す構造体型.（ex. recv(c) as v => v）
+	Token	: 分岐の最初のトークン
+	Comm	: 待ち合わせるチャネル操作（recv または send の呼び出し）. デフォルトの分岐（_ => ...）では nil
+	Value	: recv で受け取った値を束縛する識別子. なければ nil
+	Body	: 分岐が選ばれた時に評価する式
+ */
+type SelectCase struct {
+	Token token.Token
+	Comm  *CallExpression
+	Value *Identifier
+	Body  Expression
+}
+
+func (sc *SelectCase) TokenLiteral() string { return sc.Token.Literal }
+func (sc *SelectCase) String() string {
+	var out bytes.Buffer
+
+	if sc.Comm == nil {
+		out.WriteString("_")
+	} else {
+		out.WriteString(sc.Comm.String())
+	}
+	if sc.Value != nil {
+		out.WriteString(" as ")
+		out.WriteString(sc.Value.String())
+	}
+	out.WriteString(" => ")
+	out.WriteString(sc.Body.String())
+
+	return out.String()
+}
+
+/*
+	throw 文を表す構造体型.（ex. throw <expression>;）
+	Token	: 'throw' トークン
+	Value	: 送出する値を生成する式
+ */
+type ThrowStatement struct {
+	Token token.Token // 'throw' トークン
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *ThrowStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ts.TokenLiteral() + " ")
+	out.WriteString(ts.Value.String())
+	out.WriteString(";")
+
+	return out.String()
+}
+
+/*
+	try 式を表す構造体型.（ex. try { ... } catch (e) { ... } finally { ... }）
+	Token		: 'try' トークン
+	Block		: 例外を監視するブロック
+	CatchParam	: 捕捉した値を束縛する識別子. catch 節がないか, 省略された場合は nil
+	Catch		: 例外を捕捉した時に実行するブロック. なければ nil
+	Finally		: 例外の有無に関わらず最後に実行するブロック. なければ nil
+ */
+type TryExpression struct {
+	Token      token.Token // 'try' トークン
+	Block      *BlockStatement
+	CatchParam *Identifier
+	Catch      *BlockStatement
+	Finally    *BlockStatement
+}
+
+func (te *TryExpression) expressionNode()      {}
+func (te *TryExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *TryExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("try ")
+	out.WriteString(te.Block.String())
+
+	if te.Catch != nil {
+		out.WriteString(" catch")
+		if te.CatchParam != nil {
+			out.WriteString("(" + te.CatchParam.String() + ")")
+		}
+		out.WriteString(" ")
+		out.WriteString(te.Catch.String())
+	}
+
+	if te.Finally != nil {
+		out.WriteString(" finally ")
+		out.WriteString(te.Finally.String())
+	}
+
+	return out.String()
+}
+
+/*
+	import 文を表す構造体型.（ex. import "path/to/mod" as m;）
+	Token	: 'import' トークン
+	Path	: 読み込むモジュールのパス（引用符は含まない）
+	Alias	: モジュールを参照するための名前
+ */
+type ImportStatement struct {
+	Token token.Token // 'import' トークン
+	Path  string
+	Alias *Identifier
+}
+
+func (is *ImportStatement) statementNode()       {}
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *ImportStatement) String() string {
+	return is.TokenLiteral() + " \"" + is.Path + "\" as " + is.Alias.String() + ";"
+}
+
+/*
+	export 文を表す構造体型.（ex. export let <identifier> = <expression>;）
+	Token		: 'export' トークン
+	Statement	: 公開する名前を束縛する let 文
+ */
+type ExportStatement struct {
+	Token     token.Token // 'export' トークン
+	Statement *LetStatement
+}
+
+func (es *ExportStatement) statementNode()       {}
+func (es *ExportStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExportStatement) String() string {
+	return es.TokenLiteral() + " " + es.Statement.String()
+}
+
+/*
+	メンバーアクセス式を表す構造体型.（ex. m.name）
+	Token		: '.' トークン
+	Object		: メンバーを持つ値を生成する式
+	Property	: メンバーの名前
+ */
+type MemberExpression struct {
+	Token    token.Token // '.' トークン
+	Object   Expression
+	Property *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) String() string {
+	return me.Object.String() + "." + me.Property.String()
+}
+
+/*
+	マクロリテラルを表す構造体型.（ex. macro(x, y) { quote(unquote(y) - unquote(x)) }）
+	Token		: 'macro' トークン
+	Parameters	: 仮引数. 展開時に, 呼び出しの実引数の AST に束縛される
+	Body		: マクロ本体
+ */
+type MacroLiteral struct {
+	Token      token.Token // 'macro' トークン
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (ml *MacroLiteral) expressionNode()      {}
+func (ml *MacroLiteral) TokenLiteral() string { return ml.Token.Literal }
+func (ml *MacroLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range ml.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString(ml.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(ml.Body.String())
+
+	return out.String()
+}
+
+/*
+	メソッドのレシーバを表す構造体型.（ex. fn (p Point) norm() { ... } の p Point）
+	Token	: レシーバ名の IDENT トークン
+	Name	: メソッド本体でレシーバを参照する名前
+	Type	: レシーバの構造体型の名前
+ */
+type Receiver struct {
+	Token token.Token // レシーバ名の IDENT トークン
+	Name  *Identifier
+	Type  *Identifier
+}
+
+func (r *Receiver) TokenLiteral() string { return r.Token.Literal }
+func (r *Receiver) String() string {
+	return r.Name.String() + " " + r.Type.String()
+}
+
+/*
+	構造体型の宣言を表す構造体型.（ex. struct Point { x, y }）
+	Token	: 'struct' トークン
+	Name	: 構造体型の名前
+	Fields	: フィールド名（宣言順）
+ */
+type StructStatement struct {
+	Token  token.Token // 'struct' トークン
+	Name   *Identifier
+	Fields []*Identifier
+}
+
+func (ss *StructStatement) statementNode()       {}
+func (ss *StructStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *StructStatement) String() string {
+	fields := []string{}
+	for _, f := range ss.Fields {
+		fields = append(fields, f.String())
+	}
+	return ss.TokenLiteral() + " " + ss.Name.String() + " { " + strings.Join(fields, ", ") + " }"
+}
+
+/*
+	構造体の値を生成する式を表す構造体型.（ex. Point { x: 1, y: 2 }）
+	Token	: '{' トークン
+	Name	: 構造体型の名前
+	Fields	: フィールドの初期値（書かれた順）
+ */
+type StructLiteral struct {
+	Token  token.Token // '{' トークン
+	Name   *Identifier
+	Fields []*StructField
+}
+
+func (sl *StructLiteral) expressionNode()      {}
+func (sl *StructLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StructLiteral) String() string {
+	fields := []string{}
+	for _, f := range sl.Fields {
+		fields = append(fields, f.String())
+	}
+	return sl.Name.String() + " { " + strings.Join(fields, ", ") + " }"
+}
+
+/*
+	構造体の値を生成する式の, 1 つのフィールドの初期値を表す構造体型.（ex. Point { x: 1 } の x: 1）
+	Token	: フィールド名の IDENT トークン
+	Name	: フィールド名
+	Value	: 初期値の式
+ */
+type StructField struct {
+	Token token.Token // フィールド名の IDENT トークン
+	Name  *Identifier
+	Value Expression
+}
+
+func (sf *StructField) TokenLiteral() string { return sf.Token.Literal }
+func (sf *StructField) String() string {
+	return sf.Name.String() + ": " + sf.Value.String()
+}
+
+/*
+	テンプレート文字列を表す構造体型.（ex. `Hello, ${name}!`）
+	Token	: 開始の '`' トークン
+	Parts	: テキスト部分（TemplateText）と埋め込み式を, 書かれた順に並べたもの
+ */
+type TemplateLiteral struct {
+	Token token.Token // 開始の '`' トークン
+	Parts []Expression
+}
+
+func (tl *TemplateLiteral) expressionNode()      {}
+func (tl *TemplateLiteral) TokenLiteral() string { return tl.Token.Literal }
+func (tl *TemplateLiteral) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("`")
+	for _, part := range tl.Parts {
+		if text, ok := part.(*TemplateText); ok {
+			// パースし直せるように, テキストの '`' と "${" をエスケープし直す.
+			escaped := strings.Replace(text.Value, "`", "\\`", -1)
+			escaped = strings.Replace(escaped, "${", "\\${", -1)
+			out.WriteString(escaped)
+			continue
+		}
+		out.WriteString("${" + part.String() + "}")
+	}
+	out.WriteString("`")
+
+	return out.String()
+}
+
+/*
+	テンプレート文字列のテキスト部分を表す構造体型.（ex. `Hello, ${name}!` の "Hello, " と "!"）
+	Token	: token.TEMPLATE_TEXT トークン
+	Value	: テキスト. エスケープシーケンス（"\`" と "\${"）は解釈済み
+ */
+type TemplateText struct {
+	Token token.Token // token.TEMPLATE_TEXT トークン
+	Value string
+}
+
+func (tt *TemplateText) expressionNode()      {}
+func (tt *TemplateText) TokenLiteral() string { return tt.Token.Literal }
+func (tt *TemplateText) String() string       { return tt.Value }
+
+/*
+	範囲式を表す構造体型.（ex. 0..10 / a..=b）
+	Token		: '..' または '..=' トークン
+	Start		: 範囲の始まり（範囲に含む）
+	End			: 範囲の終わり. Inclusive が false なら範囲に含まない
+	Inclusive	: '..=' で書かれたか
+ */
+type RangeExpression struct {
+	Token     token.Token // '..' または '..=' トークン
+	Start     Expression
+	End       Expression
+	Inclusive bool
+}
+
+func (re *RangeExpression) expressionNode()      {}
+func (re *RangeExpression) TokenLiteral() string { return re.Token.Literal }
+func (re *RangeExpression) String() string {
+	return "(" + re.Start.String() + re.Token.Literal + re.End.String() + ")"
+}
+
+/*
+	添字式を表す構造体型.（ex. xs[1]）
+	Token	: '[' トークン
+	Left	: 添字でアクセスされる値を生成する式
+	Index	: 添字
+ */
+type IndexExpression struct {
+	Token token.Token // '[' トークン
+	Left  Expression
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) String() string {
+	return "(" + ie.Left.String() + "[" + ie.Index.String() + "])"
+}
+
+/*
+	スライス式を表す構造体型.（ex. xs[1:3] / s[:n] / xs[::-1]）
+	Token	: '[' トークン
+	Left	: スライスされる値を生成する式
+	Start	: 始まりの添字. 省略された場合は nil
+	End		: 終わりの添字（範囲に含まない）. 省略された場合は nil
+	Step	: 添字の増分. 省略された場合は nil
+ */
+type SliceExpression struct {
+	Token token.Token // '[' トークン
+	Left  Expression
+	Start Expression
+	End   Expression
+	Step  Expression
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) String() string {
+	bound := func(exp Expression) string {
+		if exp == nil {
+			return ""
+		}
+		return exp.String()
+	}
+
+	var out bytes.Buffer
+
+	out.WriteString("(" + se.Left.String() + "[")
+	out.WriteString(bound(se.Start) + ":" + bound(se.End))
+	if se.Step != nil {
+		out.WriteString(":" + se.Step.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
+/*
+	型注釈を表すノード.（ex. int / fn(int, int) -> int）
+	NamedType, FunctionType が実装する.
+	typeNode() : ダミーメソッド. コンパイルの段階で弾かせるため実装は持たなくて良い.
+ */
+type TypeAnnotation interface {
+	Node
+	typeNode()
+}
+
+/*
+	名前で指定する型を表す構造体型.（ex. int / bool / string / any / Point）
+	Token	: 型の名前の IDENT トークン
+	Name	: 型の名前
+ */
+type NamedType struct {
+	Token token.Token // 型の名前の IDENT トークン
+	Name  string
+}
+
+func (nt *NamedType) typeNode()            {}
+func (nt *NamedType) TokenLiteral() string { return nt.Token.Literal }
+func (nt *NamedType) String() string       { return nt.Name }
+
+/*
+	関数の型を表す構造体型.（ex. fn(int, int) -> int）
+	Token		: 'fn' トークン
+	Parameters	: 仮引数の型
+	Return		: 戻り値の型. 省略された場合は nil
+ */
+type FunctionType struct {
+	Token      token.Token // 'fn' トークン
+	Parameters []TypeAnnotation
+	Return     TypeAnnotation
+}
+
+func (ft *FunctionType) typeNode()            {}
+func (ft *FunctionType) TokenLiteral() string { return ft.Token.Literal }
+func (ft *FunctionType) String() string {
+	params := []string{}
+	for _, p := range ft.Parameters {
+		params = append(params, p.String())
+	}
+
+	out := ft.TokenLiteral() + "(" + strings.Join(params, ", ") + ")"
+	if ft.Return != nil {
+		out += " -> " + ft.Return.String()
+	}
+	return out
+}
@@ -26,3 +26,37 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() wrong. got=%q", program.String())
 	}
 }
+
+func TestPatternString(t *testing.T) {
+	ident := func(name string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Pattern: &ArrayPattern{
+					Token: token.Token{Type: token.LBRACKET, Literal: "["},
+					Elements: []Pattern{
+						ident("a"),
+						&HashPattern{
+							Token: token.Token{Type: token.LBRACE, Literal: "{"},
+							Entries: []*HashPatternEntry{
+								{Key: ident("name"), Value: ident("name")},
+								{Key: ident("age"), Value: ident("years")},
+							},
+						},
+					},
+					Rest: ident("rest"),
+				},
+				Value: ident("xs"),
+			},
+		},
+	}
+
+	expected := "let [a, {name, age: years}, ...rest] = xs;"
+	if program.String() != expected {
+		t.Errorf("program.String() wrong. want=%q, got=%q", expected, program.String())
+	}
+}
@@ -0,0 +1,417 @@
+package ast
+
+/*
+	AST を書き換える関数.
+	node を複製しながら子ノードから順に（帰りがけ順で）辿り, 各ノードを modifier の戻り値で置き換えた新しい AST を返す.
+	元の AST は変更しないので, 同じ AST を何度書き換えても互いに影響しない.
+	型注釈は辿らずに, 元の AST と共有する.
+	識別子でなければならないフィールド（ex. LetStatement.Name）を識別子以外に置き換えた場合は, 元のノードのままになる.
+ */
+func Modify(node Node, modifier func(Node) Node) Node {
+	switch n := node.(type) {
+	case *Program:
+		c := *n
+		c.Statements = modifyStatements(n.Statements, modifier)
+		node = &c
+
+	case *LetStatement:
+		c := *n
+		c.Name = modifyIdentifier(n.Name, modifier)
+		if n.Pattern != nil {
+			c.Pattern = modifyPattern(n.Pattern, modifier)
+		}
+		c.Value = modifyExpression(n.Value, modifier)
+		node = &c
+
+	case *ConstStatement:
+		c := *n
+		c.Name = modifyIdentifier(n.Name, modifier)
+		c.Value = modifyExpression(n.Value, modifier)
+		node = &c
+
+	case *Identifier:
+		c := *n
+		node = &c
+
+	case *ReturnStatement:
+		c := *n
+		c.ReturnValue = modifyExpression(n.ReturnValue, modifier)
+		node = &c
+
+	case *ExpressionStatement:
+		c := *n
+		c.Expression = modifyExpression(n.Expression, modifier)
+		node = &c
+
+	case *IntegerLiteral:
+		c := *n
+		node = &c
+
+	case *Boolean:
+		c := *n
+		node = &c
+
+	case *PrefixExpression:
+		c := *n
+		c.Right = modifyExpression(n.Right, modifier)
+		node = &c
+
+	case *InfixExpression:
+		c := *n
+		c.Left = modifyExpression(n.Left, modifier)
+		c.Right = modifyExpression(n.Right, modifier)
+		node = &c
+
+	case *IfExpression:
+		c := *n
+		c.Condition = modifyExpression(n.Condition, modifier)
+		c.Consequence = modifyBlock(n.Consequence, modifier)
+		c.Alternative = modifyBlock(n.Alternative, modifier)
+		node = &c
+
+	case *BlockStatement:
+		c := *n
+		c.Statements = modifyStatements(n.Statements, modifier)
+		node = &c
+
+	case *FunctionLiteral:
+		c := *n
+		if n.Receiver != nil {
+			if r, ok := Modify(n.Receiver, modifier).(*Receiver); ok {
+				c.Receiver = r
+			}
+		}
+		c.Name = modifyIdentifier(n.Name, modifier)
+		c.Parameters, c.Defaults = modifyParameters(n.Parameters, n.Defaults, modifier)
+		if n.Types != nil {
+			// 仮引数の名前が変わった場合は, 型注釈のマップのキーも新しい名前にする.
+			c.Types = map[string]TypeAnnotation{}
+			for i, p := range n.Parameters {
+				if typ, ok := n.Types[p.Value]; ok {
+					c.Types[c.Parameters[i].Value] = typ
+				}
+			}
+		}
+		c.Rest = modifyIdentifier(n.Rest, modifier)
+		c.Body = modifyBlock(n.Body, modifier)
+		node = &c
+
+	case *CallExpression:
+		c := *n
+		c.Function = modifyExpression(n.Function, modifier)
+		c.Arguments = modifyExpressions(n.Arguments, modifier)
+		if n.NamedArguments != nil {
+			c.NamedArguments = []*NamedArgument{}
+			for _, a := range n.NamedArguments {
+				if a, ok := Modify(a, modifier).(*NamedArgument); ok {
+					c.NamedArguments = append(c.NamedArguments, a)
+				}
+			}
+		}
+		node = &c
+
+	case *NamedArgument:
+		c := *n
+		c.Name = modifyIdentifier(n.Name, modifier)
+		c.Value = modifyExpression(n.Value, modifier)
+		node = &c
+
+	case *SpreadExpression:
+		c := *n
+		c.Value = modifyExpression(n.Value, modifier)
+		node = &c
+
+	case *ArrayPattern:
+		c := *n
+		c.Elements = []Pattern{}
+		for _, e := range n.Elements {
+			c.Elements = append(c.Elements, modifyPattern(e, modifier))
+		}
+		c.Rest = modifyIdentifier(n.Rest, modifier)
+		node = &c
+
+	case *HashPattern:
+		c := *n
+		c.Entries = []*HashPatternEntry{}
+		for _, e := range n.Entries {
+			if e, ok := Modify(e, modifier).(*HashPatternEntry); ok {
+				c.Entries = append(c.Entries, e)
+			}
+		}
+		node = &c
+
+	case *HashPatternEntry:
+		c := *n
+		c.Key = modifyIdentifier(n.Key, modifier)
+		c.Value = modifyPattern(n.Value, modifier)
+		node = &c
+
+	case *WildcardPattern:
+		c := *n
+		node = &c
+
+	case *LiteralPattern:
+		c := *n
+		c.Value = modifyExpression(n.Value, modifier)
+		node = &c
+
+	case *MatchExpression:
+		c := *n
+		c.Subject = modifyExpression(n.Subject, modifier)
+		c.Arms = []*MatchArm{}
+		for _, a := range n.Arms {
+			if a, ok := Modify(a, modifier).(*MatchArm); ok {
+				c.Arms = append(c.Arms, a)
+			}
+		}
+		node = &c
+
+	case *MatchArm:
+		c := *n
+		c.Pattern = modifyPattern(n.Pattern, modifier)
+		c.Guard = modifyExpression(n.Guard, modifier)
+		c.Body = modifyExpression(n.Body, modifier)
+		node = &c
+
+	case *PipeExpression:
+		c := *n
+		c.Left = modifyExpression(n.Left, modifier)
+		c.Right = modifyExpression(n.Right, modifier)
+		node = &c
+
+	case *SpawnExpression:
+		c := *n
+		if call, ok := Modify(n.Call, modifier).(*CallExpression); ok {
+			c.Call = call
+		}
+		node = &c
+
+	case *SelectExpression:
+		c := *n
+		c.Cases = []*SelectCase{}
+		for _, sc := range n.Cases {
+			if sc, ok := Modify(sc, modifier).(*SelectCase); ok {
+				c.Cases = append(c.Cases, sc)
+			}
+		}
+		node = &c
+
+	case *SelectCase:
+		c := *n
+		if n.Comm != nil {
+			if call, ok := Modify(n.Comm, modifier).(*CallExpression); ok {
+				c.Comm = call
+			}
+		}
+		c.Value = modifyIdentifier(n.Value, modifier)
+		c.Body = modifyExpression(n.Body, modifier)
+		node = &c
+
+	case *ThrowStatement:
+		c := *n
+		c.Value = modifyExpression(n.Value, modifier)
+		node = &c
+
+	case *TryExpression:
+		c := *n
+		c.Block = modifyBlock(n.Block, modifier)
+		c.CatchParam = modifyIdentifier(n.CatchParam, modifier)
+		c.Catch = modifyBlock(n.Catch, modifier)
+		c.Finally = modifyBlock(n.Finally, modifier)
+		node = &c
+
+	case *ImportStatement:
+		c := *n
+		c.Alias = modifyIdentifier(n.Alias, modifier)
+		node = &c
+
+	case *ExportStatement:
+		c := *n
+		if n.Statement != nil {
+			if stmt, ok := Modify(n.Statement, modifier).(*LetStatement); ok {
+				c.Statement = stmt
+			}
+		}
+		node = &c
+
+	case *MemberExpression:
+		c := *n
+		c.Object = modifyExpression(n.Object, modifier)
+		c.Property = modifyIdentifier(n.Property, modifier)
+		node = &c
+
+	case *Receiver:
+		c := *n
+		c.Name = modifyIdentifier(n.Name, modifier)
+		c.Type = modifyIdentifier(n.Type, modifier)
+		node = &c
+
+	case *StructStatement:
+		c := *n
+		c.Name = modifyIdentifier(n.Name, modifier)
+		c.Fields = []*Identifier{}
+		for _, f := range n.Fields {
+			c.Fields = append(c.Fields, modifyIdentifier(f, modifier))
+		}
+		node = &c
+
+	case *StructLiteral:
+		c := *n
+		c.Name = modifyIdentifier(n.Name, modifier)
+		c.Fields = []*StructField{}
+		for _, f := range n.Fields {
+			if f, ok := Modify(f, modifier).(*StructField); ok {
+				c.Fields = append(c.Fields, f)
+			}
+		}
+		node = &c
+
+	case *StructField:
+		c := *n
+		c.Name = modifyIdentifier(n.Name, modifier)
+		c.Value = modifyExpression(n.Value, modifier)
+		node = &c
+
+	case *TemplateLiteral:
+		c := *n
+		c.Parts = modifyExpressions(n.Parts, modifier)
+		node = &c
+
+	case *TemplateText:
+		c := *n
+		node = &c
+
+	case *RangeExpression:
+		c := *n
+		c.Start = modifyExpression(n.Start, modifier)
+		c.End = modifyExpression(n.End, modifier)
+		node = &c
+
+	case *IndexExpression:
+		c := *n
+		c.Left = modifyExpression(n.Left, modifier)
+		c.Index = modifyExpression(n.Index, modifier)
+		node = &c
+
+	case *SliceExpression:
+		c := *n
+		c.Left = modifyExpression(n.Left, modifier)
+		c.Start = modifyExpression(n.Start, modifier)
+		c.End = modifyExpression(n.End, modifier)
+		c.Step = modifyExpression(n.Step, modifier)
+		node = &c
+
+	case *MacroLiteral:
+		c := *n
+		c.Parameters, _ = modifyParameters(n.Parameters, nil, modifier)
+		c.Body = modifyBlock(n.Body, modifier)
+		node = &c
+	}
+
+	return modifier(node)
+}
+
+/*
+	文のスライスを書き換えるヘルパー関数. 文以外に置き換えられた要素は取り除く.
+ */
+func modifyStatements(statements []Statement, modifier func(Node) Node) []Statement {
+	if statements == nil {
+		return nil
+	}
+	modified := []Statement{}
+	for _, s := range statements {
+		if s, ok := Modify(s, modifier).(Statement); ok {
+			modified = append(modified, s)
+		}
+	}
+	return modified
+}
+
+/*
+	式のスライスを書き換えるヘルパー関数.
+ */
+func modifyExpressions(expressions []Expression, modifier func(Node) Node) []Expression {
+	if expressions == nil {
+		return nil
+	}
+	modified := []Expression{}
+	for _, e := range expressions {
+		modified = append(modified, modifyExpression(e, modifier))
+	}
+	return modified
+}
+
+/*
+	nil でない式を書き換えるヘルパー関数. 式以外に置き換えられた場合は元の式を返す.
+ */
+func modifyExpression(exp Expression, modifier func(Node) Node) Expression {
+	if exp == nil {
+		return nil
+	}
+	if modified, ok := Modify(exp, modifier).(Expression); ok {
+		return modified
+	}
+	return exp
+}
+
+/*
+	nil でない識別子を書き換えるヘルパー関数. 識別子以外に置き換えられた場合は元の識別子を返す.
+ */
+func modifyIdentifier(ident *Identifier, modifier func(Node) Node) *Identifier {
+	if ident == nil {
+		return nil
+	}
+	if modified, ok := Modify(ident, modifier).(*Identifier); ok {
+		return modified
+	}
+	return ident
+}
+
+/*
+	nil でないブロック文を書き換えるヘルパー関数. ブロック文以外に置き換えられた場合は元のブロック文を返す.
+ */
+func modifyBlock(block *BlockStatement, modifier func(Node) Node) *BlockStatement {
+	if block == nil {
+		return nil
+	}
+	if modified, ok := Modify(block, modifier).(*BlockStatement); ok {
+		return modified
+	}
+	return block
+}
+
+/*
+	nil でないパターンを書き換えるヘルパー関数. パターン以外に置き換えられた場合は元のパターンを返す.
+ */
+func modifyPattern(pattern Pattern, modifier func(Node) Node) Pattern {
+	if pattern == nil {
+		return nil
+	}
+	if modified, ok := Modify(pattern, modifier).(Pattern); ok {
+		return modified
+	}
+	return pattern
+}
+
+/*
+	仮引数とデフォルト値を書き換えるヘルパー関数.
+	仮引数の名前が変わった場合は, デフォルト値のマップのキーも新しい名前にする.
+ */
+func modifyParameters(params []*Identifier, defaults map[string]Expression,
+	modifier func(Node) Node) ([]*Identifier, map[string]Expression) {
+
+	var modifiedDefaults map[string]Expression
+	if defaults != nil {
+		modifiedDefaults = map[string]Expression{}
+	}
+
+	modifiedParams := []*Identifier{}
+	for _, p := range params {
+		modified := modifyIdentifier(p, modifier)
+		modifiedParams = append(modifiedParams, modified)
+		if def, ok := defaults[p.Value]; ok {
+			modifiedDefaults[modified.Value] = modifyExpression(def, modifier)
+		}
+	}
+	return modifiedParams, modifiedDefaults
+}
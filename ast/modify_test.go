@@ -0,0 +1,106 @@
+package ast
+
+import (
+	"github.com/WTBacon/goInterpreter/token"
+	"testing"
+)
+
+func TestModify(t *testing.T) {
+	one := func() Expression { return &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1} }
+	two := func() Expression { return &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "2"}, Value: 2} }
+	ident := func(name string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+
+	turnOneIntoTwo := func(node Node) Node {
+		integer, ok := node.(*IntegerLiteral)
+		if !ok || integer.Value != 1 {
+			return node
+		}
+		return two()
+	}
+
+	tests := []struct {
+		input    Node
+		expected string
+	}{
+		{one(), "2"},
+		{&Program{Statements: []Statement{&ExpressionStatement{Expression: one()}}}, "2"},
+		{&InfixExpression{Left: one(), Operator: "+", Right: two()}, "(2 + 2)"},
+		{&PrefixExpression{Operator: "-", Right: one()}, "(-2)"},
+		{
+			&IfExpression{
+				Condition:   one(),
+				Consequence: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+				Alternative: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+			},
+			"if2 2else 2",
+		},
+		{&ReturnStatement{Token: token.Token{Literal: "return"}, ReturnValue: one()}, "return 2;"},
+		{&LetStatement{Token: token.Token{Literal: "let"}, Name: ident("x"), Value: one()}, "let x = 2;"},
+		{
+			&FunctionLiteral{
+				Token:      token.Token{Literal: "fn"},
+				Parameters: []*Identifier{ident("x")},
+				Defaults:   map[string]Expression{"x": one()},
+				Body:       &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+			},
+			"fn(x = 2) 2",
+		},
+		{
+			&CallExpression{
+				Function:       ident("f"),
+				Arguments:      []Expression{one(), &SpreadExpression{Value: one()}},
+				NamedArguments: []*NamedArgument{{Name: ident("y"), Value: one()}},
+			},
+			"f(2, ...2, y: 2)",
+		},
+		{
+			&MatchExpression{
+				Subject: one(),
+				Arms: []*MatchArm{
+					{Pattern: &LiteralPattern{Value: one()}, Guard: one(), Body: one()},
+				},
+			},
+			"match (2) { 2 if 2 => 2 }",
+		},
+		{&PipeExpression{Left: one(), Right: ident("f")}, "(2 |> f)"},
+		{&MemberExpression{Object: one(), Property: ident("x")}, "2.x"},
+	}
+
+	for _, tt := range tests {
+		before := tt.input.String()
+		modified := Modify(tt.input, turnOneIntoTwo)
+
+		if modified.String() != tt.expected {
+			t.Errorf("not equal. want=%q, got=%q", tt.expected, modified.String())
+		}
+		if tt.input.String() != before {
+			t.Errorf("input was modified. want=%q, got=%q", before, tt.input.String())
+		}
+	}
+}
+
+func TestModifyRenamesParameterDefaults(t *testing.T) {
+	x := &Identifier{Token: token.Token{Type: token.IDENT, Literal: "x"}, Value: "x"}
+	function := &FunctionLiteral{
+		Token:      token.Token{Literal: "fn"},
+		Parameters: []*Identifier{x},
+		Defaults:   map[string]Expression{"x": &Boolean{Token: token.Token{Literal: "true"}, Value: true}},
+		Body:       &BlockStatement{},
+	}
+
+	modified := Modify(function, func(node Node) Node {
+		if ident, ok := node.(*Identifier); ok && ident.Value == "x" {
+			ident.Value = "y"
+		}
+		return node
+	}).(*FunctionLiteral)
+
+	if _, ok := modified.Defaults["y"]; !ok {
+		t.Errorf("default was not moved to renamed parameter. got=%v", modified.Defaults)
+	}
+	if _, ok := function.Defaults["x"]; !ok || x.Value != "x" {
+		t.Errorf("original function was modified")
+	}
+}
@@ -0,0 +1,280 @@
+package ast
+
+/*
+	AST を深さ優先で辿り, 各ノードで fn を呼ぶ関数.
+	fn が false を返したノードの子ノードは辿らない.
+	子ノードはソースコード上に現れる順に辿る. nil のフィールドは読み飛ばす.
+ */
+func Walk(node Node, fn func(Node) bool) {
+	if !fn(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		for _, s := range n.Statements {
+			Walk(s, fn)
+		}
+
+	case *LetStatement:
+		if n.Name != nil {
+			Walk(n.Name, fn)
+		}
+		if n.Pattern != nil {
+			Walk(n.Pattern, fn)
+		}
+		if n.Type != nil {
+			Walk(n.Type, fn)
+		}
+		walkExpression(n.Value, fn)
+
+	case *ConstStatement:
+		Walk(n.Name, fn)
+		walkExpression(n.Value, fn)
+
+	case *ReturnStatement:
+		walkExpression(n.ReturnValue, fn)
+
+	case *ExpressionStatement:
+		walkExpression(n.Expression, fn)
+
+	case *BlockStatement:
+		for _, s := range n.Statements {
+			Walk(s, fn)
+		}
+
+	case *ThrowStatement:
+		walkExpression(n.Value, fn)
+
+	case *ImportStatement:
+		if n.Alias != nil {
+			Walk(n.Alias, fn)
+		}
+
+	case *ExportStatement:
+		if n.Statement != nil {
+			Walk(n.Statement, fn)
+		}
+
+	case *PrefixExpression:
+		walkExpression(n.Right, fn)
+
+	case *InfixExpression:
+		walkExpression(n.Left, fn)
+		walkExpression(n.Right, fn)
+
+	case *IfExpression:
+		walkExpression(n.Condition, fn)
+		if n.Consequence != nil {
+			Walk(n.Consequence, fn)
+		}
+		if n.Alternative != nil {
+			Walk(n.Alternative, fn)
+		}
+
+	case *FunctionLiteral:
+		if n.Receiver != nil {
+			Walk(n.Receiver, fn)
+		}
+		if n.Name != nil {
+			Walk(n.Name, fn)
+		}
+		for _, p := range n.Parameters {
+			Walk(p, fn)
+			if typ, ok := n.Types[p.Value]; ok {
+				Walk(typ, fn)
+			}
+			walkExpression(n.Defaults[p.Value], fn)
+		}
+		if n.Rest != nil {
+			Walk(n.Rest, fn)
+		}
+		if n.ReturnType != nil {
+			Walk(n.ReturnType, fn)
+		}
+		if n.Body != nil {
+			Walk(n.Body, fn)
+		}
+
+	case *CallExpression:
+		walkExpression(n.Function, fn)
+		for _, a := range n.Arguments {
+			walkExpression(a, fn)
+		}
+		for _, a := range n.NamedArguments {
+			Walk(a, fn)
+		}
+
+	case *NamedArgument:
+		Walk(n.Name, fn)
+		walkExpression(n.Value, fn)
+
+	case *SpreadExpression:
+		walkExpression(n.Value, fn)
+
+	case *ArrayPattern:
+		for _, e := range n.Elements {
+			Walk(e, fn)
+		}
+		if n.Rest != nil {
+			Walk(n.Rest, fn)
+		}
+
+	case *HashPattern:
+		for _, e := range n.Entries {
+			Walk(e, fn)
+		}
+
+	case *HashPatternEntry:
+		Walk(n.Key, fn)
+		Walk(n.Value, fn)
+
+	case *LiteralPattern:
+		walkExpression(n.Value, fn)
+
+	case *MatchExpression:
+		walkExpression(n.Subject, fn)
+		for _, a := range n.Arms {
+			Walk(a, fn)
+		}
+
+	case *MatchArm:
+		Walk(n.Pattern, fn)
+		walkExpression(n.Guard, fn)
+		walkExpression(n.Body, fn)
+
+	case *PipeExpression:
+		walkExpression(n.Left, fn)
+		walkExpression(n.Right, fn)
+
+	case *SpawnExpression:
+		if n.Call != nil {
+			Walk(n.Call, fn)
+		}
+
+	case *SelectExpression:
+		for _, c := range n.Cases {
+			Walk(c, fn)
+		}
+
+	case *SelectCase:
+		if n.Comm != nil {
+			Walk(n.Comm, fn)
+		}
+		if n.Value != nil {
+			Walk(n.Value, fn)
+		}
+		walkExpression(n.Body, fn)
+
+	case *TryExpression:
+		if n.Block != nil {
+			Walk(n.Block, fn)
+		}
+		if n.CatchParam != nil {
+			Walk(n.CatchParam, fn)
+		}
+		if n.Catch != nil {
+			Walk(n.Catch, fn)
+		}
+		if n.Finally != nil {
+			Walk(n.Finally, fn)
+		}
+
+	case *MemberExpression:
+		walkExpression(n.Object, fn)
+		if n.Property != nil {
+			Walk(n.Property, fn)
+		}
+
+	case *Receiver:
+		Walk(n.Name, fn)
+		Walk(n.Type, fn)
+
+	case *StructStatement:
+		Walk(n.Name, fn)
+		for _, f := range n.Fields {
+			Walk(f, fn)
+		}
+
+	case *StructLiteral:
+		Walk(n.Name, fn)
+		for _, f := range n.Fields {
+			Walk(f, fn)
+		}
+
+	case *StructField:
+		Walk(n.Name, fn)
+		walkExpression(n.Value, fn)
+
+	case *TemplateLiteral:
+		for _, part := range n.Parts {
+			walkExpression(part, fn)
+		}
+
+	case *RangeExpression:
+		walkExpression(n.Start, fn)
+		walkExpression(n.End, fn)
+
+	case *IndexExpression:
+		walkExpression(n.Left, fn)
+		walkExpression(n.Index, fn)
+
+	case *SliceExpression:
+		walkExpression(n.Left, fn)
+		walkExpression(n.Start, fn)
+		walkExpression(n.End, fn)
+		walkExpression(n.Step, fn)
+
+	case *FunctionType:
+		for _, p := range n.Parameters {
+			Walk(p, fn)
+		}
+		if n.Return != nil {
+			Walk(n.Return, fn)
+		}
+
+	case *MacroLiteral:
+		for _, p := range n.Parameters {
+			Walk(p, fn)
+		}
+		if n.Body != nil {
+			Walk(n.Body, fn)
+		}
+	}
+}
+
+/*
+	nil でない式だけを辿るヘルパー関数.
+ */
+func walkExpression(exp Expression, fn func(Node) bool) {
+	if exp != nil {
+		Walk(exp, fn)
+	}
+}
+
+/*
+	パターンが束縛する識別子を, 出現順に返す関数.
+	HashPattern のキーや WildcardPattern, LiteralPattern は何も束縛しないので含まない.
+ */
+func PatternIdentifiers(pattern Pattern) []*Identifier {
+	switch pattern := pattern.(type) {
+	case *Identifier:
+		return []*Identifier{pattern}
+	case *ArrayPattern:
+		idents := []*Identifier{}
+		for _, e := range pattern.Elements {
+			idents = append(idents, PatternIdentifiers(e)...)
+		}
+		if pattern.Rest != nil {
+			idents = append(idents, pattern.Rest)
+		}
+		return idents
+	case *HashPattern:
+		idents := []*Identifier{}
+		for _, e := range pattern.Entries {
+			idents = append(idents, PatternIdentifiers(e.Value)...)
+		}
+		return idents
+	}
+	return nil
+}
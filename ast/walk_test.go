@@ -0,0 +1,77 @@
+package ast
+
+import (
+	"github.com/WTBacon/goInterpreter/token"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	ident := func(name string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+
+	// let f = fn(x, y = z) { g(x, ...y) };
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name:  ident("f"),
+				Value: &FunctionLiteral{
+					Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+					Parameters: []*Identifier{ident("x"), ident("y")},
+					Defaults:   map[string]Expression{"y": ident("z")},
+					Body: &BlockStatement{
+						Token: token.Token{Type: token.LBRACE, Literal: "{"},
+						Statements: []Statement{
+							&ExpressionStatement{
+								Token: token.Token{Type: token.IDENT, Literal: "g"},
+								Expression: &CallExpression{
+									Token:    token.Token{Type: token.LPAREN, Literal: "("},
+									Function: ident("g"),
+									Arguments: []Expression{
+										ident("x"),
+										&SpreadExpression{
+											Token: token.Token{Type: token.ELLIPSIS, Literal: "..."},
+											Value: ident("y"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	visited := []string{}
+	Walk(program, func(node Node) bool {
+		if ident, ok := node.(*Identifier); ok {
+			visited = append(visited, ident.Value)
+		}
+		return true
+	})
+
+	expected := []string{"f", "x", "y", "z", "g", "x", "y"}
+	if len(visited) != len(expected) {
+		t.Fatalf("wrong identifiers visited. want=%v, got=%v", expected, visited)
+	}
+	for i, name := range expected {
+		if visited[i] != name {
+			t.Errorf("visited[%d] wrong. want=%s, got=%s", i, name, visited[i])
+		}
+	}
+
+	skipped := []string{}
+	Walk(program, func(node Node) bool {
+		if ident, ok := node.(*Identifier); ok {
+			skipped = append(skipped, ident.Value)
+		}
+		_, isFunction := node.(*FunctionLiteral)
+		return !isFunction
+	})
+
+	if len(skipped) != 1 || skipped[0] != "f" {
+		t.Errorf("children of FunctionLiteral were not skipped. got=%v", skipped)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"flag"
+	"github.com/WTBacon/goInterpreter/ast"
+	"github.com/WTBacon/goInterpreter/loader"
+	"github.com/WTBacon/goInterpreter/macro"
+	"github.com/WTBacon/goInterpreter/parser"
+	"github.com/WTBacon/goInterpreter/resolver"
+	"github.com/WTBacon/goInterpreter/types"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+)
+
+/*
+	bacon check [-path dir[:dir...]] <file>... : ソースファイルを実行せずに型検査する.
+	import するモジュールも読み込んで構文を検査し, マクロを展開してから名前を解決して型検査する.
+	-path には, 相対パス以外の import を探すディレクトリを OS のパス区切り文字で区切って指定する.
+	エラーがなければ 0 を, あれば 1 を, 引数が正しくなければ 2 を返す.
+	警告（import したモジュールも含めたパーサーの警告と, 名前解決の警告）は表示するだけで, 結果には影響しない.
+ */
+func check(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	searchPath := flags.String("path", "", "")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		fmt.Fprintln(out, "usage: bacon check [-path dir[:dir...]] <file>...")
+		return 2
+	}
+	paths := flags.Args()
+
+	// 診断メッセージにパーサーのトレースが混ざらないようにする.
+	parser.TraceOutput = ioutil.Discard
+
+	status := 0
+	for _, path := range paths {
+		l := loader.New(filepath.SplitList(*searchPath)...)
+		mod, err := l.Load(path)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			status = 1
+			continue
+		}
+
+		for _, m := range modules(mod) {
+			display := path
+			if m != mod {
+				display = l.DisplayPath(m.Path)
+			}
+			for _, w := range m.Warnings {
+				fmt.Fprintf(out, "%s: warning: %s\n", display, w)
+			}
+		}
+
+		macroEnv := macro.NewEnv()
+		macro.DefineMacros(mod.Program, macroEnv)
+		expanded, err := macro.ExpandMacros(mod.Program, macroEnv)
+		if err != nil {
+			fmt.Fprintf(out, "%s: macro expansion error: %s\n", path, err)
+			status = 1
+			continue
+		}
+
+		program := expanded.(*ast.Program)
+		_, undefined, warnings := resolver.Resolve(program)
+		for _, e := range undefined {
+			fmt.Fprintf(out, "%s:%s\n", path, e)
+			status = 1
+		}
+		for _, w := range warnings {
+			fmt.Fprintf(out, "%s:%d:%d: warning: %s\n", path, w.Line, w.Column, w.Message)
+		}
+
+		_, errors := types.Check(program)
+		for _, e := range errors {
+			fmt.Fprintf(out, "%s:%s\n", path, e)
+			status = 1
+		}
+	}
+	return status
+}
+
+/*
+	mod と, mod から直接または間接に import されるモジュールを, import 文の順に重複なく返す関数.
+ */
+func modules(mod *loader.Module) []*loader.Module {
+	result := []*loader.Module{}
+	seen := map[*loader.Module]bool{}
+
+	var visit func(m *loader.Module)
+	visit = func(m *loader.Module) {
+		if seen[m] {
+			return
+		}
+		seen[m] = true
+		result = append(result, m)
+		for _, stmt := range m.Program.Statements {
+			if imp, ok := stmt.(*ast.ImportStatement); ok {
+				visit(m.Imports[imp.Alias.Value])
+			}
+		}
+	}
+	visit(mod)
+	return result
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		source         string
+		expectedStatus int
+		expectedOutput []string // ファイルのパスを除いた出力の各行
+	}{
+		{"let x = 1\nx + 1\n", 0, []string{}},
+		{
+			"let x = 1\nlet f = fn() { let x = 2; x }\nmatch (x) { _ => 1, 2 => 3 }\nf\n",
+			0,
+			[]string{
+				`: warning: match arm "_" matches every value; 1 arm(s) after it are unreachable`,
+				": warning: x at line 2, column 20 shadows the outer x declared at line 1, column 5",
+			},
+		},
+		{"let inc = fn(a) { a + 1 }\ninc(true)\n", 1,
+			[]string{":2:5: cannot use true (type bool) as type int in argument to inc"}},
+	}
+
+	for _, tt := range tests {
+		dir, err := ioutil.TempDir("", "bacon-check")
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "main.bacon")
+		if err := ioutil.WriteFile(path, []byte(tt.source), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		status := check([]string{path}, &out)
+		os.RemoveAll(dir)
+
+		if status != tt.expectedStatus {
+			t.Errorf("source %q: wrong status. want=%d, got=%d", tt.source, tt.expectedStatus, status)
+		}
+
+		expected := []string{}
+		for _, line := range tt.expectedOutput {
+			expected = append(expected, path+line)
+		}
+		output := strings.TrimSuffix(out.String(), "\n")
+		if output != strings.Join(expected, "\n") {
+			t.Errorf("source %q: wrong output.\nwant=%q\ngot=%q", tt.source, strings.Join(expected, "\n"), output)
+		}
+	}
+}
+
+func TestCheckImports(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bacon-check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"app/main.bacon":    "import \"./lib\" as lib;\nimport \"util\" as util;\nlib.x + util.y\n",
+		"app/lib.bacon":     "export let x = 1;\nmatch (x) { _ => 1, 2 => 3 };\n",
+		"stdlib/util.bacon": "export let y = 2;\nlet f = fn() { let y = 3; y };\n",
+	}
+	for name, source := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	root := filepath.Join(dir, "app", "main.bacon")
+	status := check([]string{"-path", filepath.Join(dir, "stdlib"), root}, &out)
+	if status != 0 {
+		t.Errorf("wrong status. want=0, got=%d", status)
+	}
+
+	expected := strings.Join([]string{
+		`lib.bacon: warning: match arm "_" matches every value; 1 arm(s) after it are unreachable`,
+		filepath.Join(dir, "stdlib", "util.bacon") + ": warning: y at line 2, column 20 shadows the outer y declared at line 1, column 12",
+	}, "\n")
+	if output := strings.TrimSuffix(out.String(), "\n"); output != expected {
+		t.Errorf("wrong output.\nwant=%q\ngot=%q", expected, output)
+	}
+
+	out.Reset()
+	if status := check([]string{root}, &out); status != 1 {
+		t.Errorf("without -path: wrong status. want=1, got=%d", status)
+	}
+}
+
+func TestCheckUsage(t *testing.T) {
+	tests := [][]string{
+		nil,
+		{"-path", "lib"},
+		{"-unknown", "main.bacon"},
+	}
+
+	for _, args := range tests {
+		var out bytes.Buffer
+		if status := check(args, &out); status != 2 {
+			t.Errorf("args %q: wrong status. want=2, got=%d", args, status)
+		}
+		if out.String() != "usage: bacon check [-path dir[:dir...]] <file>...\n" {
+			t.Errorf("args %q: wrong output. got=%q", args, out.String())
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package lexer
 
-import "github.com/WTBacon/goInterpreter/token"
+import (
+	"github.com/WTBacon/goInterpreter/token"
+	"strings"
+)
 
 /*
 	字句解析器（レキサー）を表す構造体型.
@@ -8,6 +11,10 @@ import "github.com/WTBacon/goInterpreter/token"
 	position 		: 常に最後に読んだ位置を示す（chの位置を示すインデクス）
 	readPosition 	: 次に読み込む位置を示す
 	ch         		: 現在検査中の文字
+	line			: ch がある行番号（1 始まり）
+	lineStart		: ch がある行の先頭のインデクス
+	templates		: 読み込み中のテンプレート文字列のスタック. 要素は ${ } の中で開いている '{' の数で,
+					  テンプレート文字列のテキスト部分を読み込んでいる間は -1
 }
  */
 type Lexer struct {
@@ -15,6 +22,9 @@ type Lexer struct {
 	position     int    // 常に最後に読んだ位置を示す（chの位置を示すインデクス）
 	readPosition int    // 次に読み込む位置を示す
 	ch           byte   // 現在検査中の文字
+	line         int    // ch がある行番号
+	lineStart    int    // ch がある行の先頭のインデクス
+	templates    []int  // 読み込み中のテンプレート文字列のスタック
 }
 
 /*
@@ -22,7 +32,7 @@ type Lexer struct {
 	readChar() で初期化.
  */
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
@@ -30,9 +40,14 @@ func New(input string) *Lexer {
 /*
 	ソースコードの次の一文字（readPosition）を読んで, 現在位置（position）を進める.
 	「ch = 0」は「まだ何も読み込んでいない」もしくは「ファイルの終わり」を表す.
+	改行を読み終えたら, 行番号（line）と行の先頭（lineStart）を更新する.
 	TODO: Bacon で Unicode と絵文字をサポートする.
  */
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line += 1
+		l.lineStart = l.readPosition
+	}
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
@@ -42,15 +57,38 @@ func (l *Lexer) readChar() {
 	l.readPosition += 1
 }
 
+/*
+	次の Bacon の Token を返す.
+	空白を読み飛ばした後にトークンを読み込み, トークンの開始位置と,
+	直前のトークンとの間に改行があったかを Token に記録する.
+ */
+func (l *Lexer) NextToken() token.Token {
+	if l.inTemplateText() {
+		// テンプレート文字列のテキスト部分では, 空白も文字列の一部になる.
+		line, column := l.line, l.position-l.lineStart+1
+		tok := l.readTemplateText()
+		tok.Line = line
+		tok.Column = column
+		return tok
+	}
+
+	afterNewline := l.skipWhitespace()
+	line, column := l.line, l.position-l.lineStart+1
+
+	tok := l.readToken()
+	tok.Line = line
+	tok.Column = column
+	tok.AfterNewline = afterNewline
+	return tok
+}
+
 /*
 	現在検査中の文字（ch） に一致する Bacon の Token を返す.
 	Token を返す前に, 入力のポインタを返す.
  */
-func (l *Lexer) NextToken() token.Token {
+func (l *Lexer) readToken() token.Token {
 	var tok token.Token
 
-	l.skipWhitespace()
-
 	switch l.ch {
 
 	case '=':
@@ -59,13 +97,25 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			literal := string(ch) + string(l.ch)
 			tok = token.Token{Type: token.EQ, Literal: literal}
+		} else if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.ARROW, Literal: literal}
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '+':
 		tok = newToken(token.PLUS, l.ch)
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.THIN_ARROW, Literal: literal}
+		} else {
+			tok = newToken(token.MINUS, l.ch)
+		}
 	case '!':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -83,14 +133,60 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.LT, l.ch)
 	case '>':
 		tok = newToken(token.GT, l.ch)
+	case '|':
+		if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.PIPE, Literal: literal}
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
+	case ':':
+		tok = newToken(token.COLON, l.ch)
+	case '.':
+		if l.peekChar() == '.' && l.peekCharAt(1) == '.' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else if l.peekChar() == '.' && l.peekCharAt(1) == '=' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.RANGE_INCLUSIVE, Literal: "..="}
+		} else if l.peekChar() == '.' {
+			l.readChar()
+			tok = token.Token{Type: token.RANGE, Literal: ".."}
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
+	case '"':
+		if literal, ok := l.readString(); ok {
+			tok = token.Token{Type: token.STRING, Literal: literal}
+		} else {
+			tok = token.Token{Type: token.ILLEGAL, Literal: literal}
+		}
+	case '`':
+		l.templates = append(l.templates, -1)
+		tok = newToken(token.BACKTICK, l.ch)
 	case '{':
+		if n := len(l.templates); n > 0 {
+			l.templates[n-1] += 1
+		}
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
+		// ${ } の中で開いた '{' が全て閉じていれば, '}' は埋め込み式の終わりを表す.
+		if n := len(l.templates); n > 0 {
+			l.templates[n-1] -= 1
+		}
 		tok = newToken(token.RBRACE, l.ch)
+	case '[':
+		tok = newToken(token.LBRACKET, l.ch)
+	case ']':
+		tok = newToken(token.RBRACKET, l.ch)
 	case '(':
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
@@ -127,6 +223,71 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
+/*
+	ch が '"' であれば, 閉じる '"' までポインタを進めて, 引用符の間の文字列を返す.
+	閉じる '"' の前に改行かファイルの終わりが来た場合は, 読み込んだ文字列と false を返す.
+ */
+func (l *Lexer) readString() (string, bool) {
+	position := l.position + 1
+	for {
+		l.readChar()
+		if l.ch == '"' {
+			return l.input[position:l.position], true
+		}
+		if l.ch == '\n' || l.ch == 0 {
+			return l.input[position-1 : l.position], false
+		}
+	}
+}
+
+/*
+	テンプレート文字列のテキスト部分を読み込んでいるか判定するヘルパーメソッド.
+ */
+func (l *Lexer) inTemplateText() bool {
+	n := len(l.templates)
+	return n > 0 && l.templates[n-1] < 0
+}
+
+/*
+	テンプレート文字列のテキスト部分から, 次のトークンを読み込むメソッド.
+	テキストは改行を含めて書かれた通りに読み込む. エスケープシーケンスは "\`" と "\${" だけを解釈して,
+	それぞれ '`' と "${" をテキストとして読み込む. それ以外の '\' は書かれた通りに読み込む.
+	'`' でテンプレート文字列を閉じ, "${" で埋め込み式を始める.
+	閉じる '`' の前にファイルの終わりが来た場合は, token.ILLEGAL トークンを返す.
+ */
+func (l *Lexer) readTemplateText() token.Token {
+	n := len(l.templates)
+
+	switch {
+	case l.ch == '`':
+		l.templates = l.templates[:n-1]
+		tok := newToken(token.BACKTICK, l.ch)
+		l.readChar()
+		return tok
+	case l.ch == '$' && l.peekChar() == '{':
+		l.templates[n-1] = 0
+		l.readChar()
+		l.readChar()
+		return token.Token{Type: token.INTERPOLATION, Literal: "${"}
+	case l.ch == 0:
+		l.templates = l.templates[:n-1]
+		return token.Token{Type: token.ILLEGAL, Literal: ""}
+	}
+
+	var text strings.Builder
+	for l.ch != '`' && l.ch != 0 && !(l.ch == '$' && l.peekChar() == '{') {
+		if l.ch == '\\' {
+			rest := l.input[l.readPosition:]
+			if strings.HasPrefix(rest, "`") || strings.HasPrefix(rest, "${") {
+				l.readChar()
+			}
+		}
+		text.WriteByte(l.ch)
+		l.readChar()
+	}
+	return token.Token{Type: token.TEMPLATE_TEXT, Literal: text.String()}
+}
+
 /*
 	ch が整数であれば, 読み終えるまでポインタを進めて, 読み込んだ整数を文字列で返す.
  */
@@ -150,13 +311,30 @@ func (l *Lexer) peekChar() byte {
 	}
 }
 
+/*
+	readPosition から offset 文字先の文字を先読みするヘルパーメソッド.
+	「...」のような 3 文字以上のトークンを判定するために使う.
+ */
+func (l *Lexer) peekCharAt(offset int) byte {
+	if l.readPosition+offset >= len(l.input) {
+		return 0
+	}
+	return l.input[l.readPosition+offset]
+}
+
 /*
 	スペースやタブ, 改行を読み飛ばすためのヘルパーメソッド.
+	読み飛ばした中に改行があれば true を返す.
  */
-func (l *Lexer) skipWhitespace() {
+func (l *Lexer) skipWhitespace() bool {
+	newline := false
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+		if l.ch == '\n' {
+			newline = true
+		}
 		l.readChar()
 	}
+	return newline
 }
 
 /*
@@ -30,6 +30,19 @@ func TestNextToken(t *testing.T) {
 
 		10 == 10;
 		10 != 9;
+		f(...xs, y: 2);
+		let [a] = xs;
+		match (a) { _ => 1 }
+		xs |> sum
+		try { throw e; } catch (e) {} finally {}
+		import "lib/strings" as str;
+		export let n = str.len;
+		macro(x) { quote(x) }
+		struct
+		0..10 a..=b
+		const
+		fn(x: int) -> int
+		spawn select
 		`
 
 	/*
@@ -112,6 +125,91 @@ func TestNextToken(t *testing.T) {
 		{token.NOT_EQ, "!="},
 		{token.INT, "9"},
 		{token.SEMICOLON, ";"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "xs"},
+		{token.COMMA, ","},
+		{token.IDENT, "y"},
+		{token.COLON, ":"},
+		{token.INT, "2"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.LBRACKET, "["},
+		{token.IDENT, "a"},
+		{token.RBRACKET, "]"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "xs"},
+		{token.SEMICOLON, ";"},
+		{token.MATCH, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "a"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.IDENT, "xs"},
+		{token.PIPE, "|>"},
+		{token.IDENT, "sum"},
+		{token.TRY, "try"},
+		{token.LBRACE, "{"},
+		{token.THROW, "throw"},
+		{token.IDENT, "e"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+		{token.CATCH, "catch"},
+		{token.LPAREN, "("},
+		{token.IDENT, "e"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.RBRACE, "}"},
+		{token.FINALLY, "finally"},
+		{token.LBRACE, "{"},
+		{token.RBRACE, "}"},
+		{token.IMPORT, "import"},
+		{token.STRING, "lib/strings"},
+		{token.AS, "as"},
+		{token.IDENT, "str"},
+		{token.SEMICOLON, ";"},
+		{token.EXPORT, "export"},
+		{token.LET, "let"},
+		{token.IDENT, "n"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "str"},
+		{token.DOT, "."},
+		{token.IDENT, "len"},
+		{token.SEMICOLON, ";"},
+		{token.MACRO, "macro"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "quote"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.RBRACE, "}"},
+		{token.STRUCT, "struct"},
+		{token.INT, "0"},
+		{token.RANGE, ".."},
+		{token.INT, "10"},
+		{token.IDENT, "a"},
+		{token.RANGE_INCLUSIVE, "..="},
+		{token.IDENT, "b"},
+		{token.CONST, "const"},
+		{token.FUNCTION, "fn"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.COLON, ":"},
+		{token.IDENT, "int"},
+		{token.RPAREN, ")"},
+		{token.THIN_ARROW, "->"},
+		{token.IDENT, "int"},
+		{token.SPAWN, "spawn"},
+		{token.SELECT, "select"},
 		{token.EOF, ""},
 	}
 
@@ -134,3 +232,140 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5\n  x +\n\n\tfoo(1)"
+
+	tests := []struct {
+		expectedType         token.TokenType
+		expectedLine         int
+		expectedColumn       int
+		expectedAfterNewline bool
+	}{
+		{token.LET, 1, 1, false},
+		{token.IDENT, 1, 5, false},
+		{token.ASSIGN, 1, 7, false},
+		{token.INT, 1, 9, false},
+		{token.IDENT, 2, 3, true},
+		{token.PLUS, 2, 5, false},
+		{token.IDENT, 4, 2, true},
+		{token.LPAREN, 4, 5, false},
+		{token.INT, 4, 6, false},
+		{token.RPAREN, 4, 7, false},
+		{token.EOF, 4, 8, false},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("test[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Errorf("test[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+		if tok.AfterNewline != tt.expectedAfterNewline {
+			t.Errorf("test[%d] - AfterNewline wrong. expected=%t, got=%t",
+				i, tt.expectedAfterNewline, tok.AfterNewline)
+		}
+	}
+}
+
+func TestTemplateLiterals(t *testing.T) {
+	input := "`Hi ${name}, ${ {a}.b + `${n} ` }!\n  raw \\n $x`;`${}`"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.BACKTICK, "`"},
+		{token.TEMPLATE_TEXT, "Hi "},
+		{token.INTERPOLATION, "${"},
+		{token.IDENT, "name"},
+		{token.RBRACE, "}"},
+		{token.TEMPLATE_TEXT, ", "},
+		{token.INTERPOLATION, "${"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "a"},
+		{token.RBRACE, "}"},
+		{token.DOT, "."},
+		{token.IDENT, "b"},
+		{token.PLUS, "+"},
+		{token.BACKTICK, "`"},
+		{token.INTERPOLATION, "${"},
+		{token.IDENT, "n"},
+		{token.RBRACE, "}"},
+		{token.TEMPLATE_TEXT, " "},
+		{token.BACKTICK, "`"},
+		{token.RBRACE, "}"},
+		{token.TEMPLATE_TEXT, "!\n  raw \\n $x"},
+		{token.BACKTICK, "`"},
+		{token.SEMICOLON, ";"},
+		{token.BACKTICK, "`"},
+		{token.INTERPOLATION, "${"},
+		{token.RBRACE, "}"},
+		{token.BACKTICK, "`"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestTemplateEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string // テンプレート文字列の中のトークンのリテラル
+	}{
+		{"`a \\` b`", []string{"a ` b"}},
+		{"`\\${x}`", []string{"${x}"}},
+		{"`\\$${x}`", []string{"\\$", "${", "x", "}"}},
+		{"`\\n \\ $`", []string{"\\n \\ $"}},
+		{"`line\n\\`quoted\\`\n`", []string{"line\n`quoted`\n"}},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		if tok := l.NextToken(); tok.Type != token.BACKTICK {
+			t.Fatalf("input %q: first token is not BACKTICK. got=%q", tt.input, tok.Type)
+		}
+		for i, expected := range tt.expected {
+			tok := l.NextToken()
+			if tok.Literal != expected {
+				t.Errorf("input %q: token %d wrong. want=%q, got=%q", tt.input, i, expected, tok.Literal)
+			}
+		}
+		if tok := l.NextToken(); tok.Type != token.BACKTICK {
+			t.Errorf("input %q: template is not closed. got=%q %q", tt.input, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestUnterminatedTemplateLiteral(t *testing.T) {
+	l := New("`abc")
+
+	expected := []token.TokenType{token.BACKTICK, token.TEMPLATE_TEXT, token.ILLEGAL, token.EOF}
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt, tok.Type)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package loader
+
+import (
+	"fmt"
+	"github.com/WTBacon/goInterpreter/ast"
+	"github.com/WTBacon/goInterpreter/lexer"
+	"github.com/WTBacon/goInterpreter/parser"
+	"github.com/WTBacon/goInterpreter/resolver"
+	"github.com/WTBacon/goInterpreter/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+	Bacon のソースファイルの拡張子.
+	import 文のパスに拡張子がなければ, この拡張子を補う.
+ */
+const Extension = ".bacon"
+
+/*
+	読み込んだモジュールを表す構造体型.
+	Path	: モジュールのソースファイルの絶対パス
+	Program	: モジュールのソースコードをパースした AST
+	Imports	: import 文のエイリアスから, 読み込んだモジュールへのマップ
+	Exports	: export 文で公開された名前から, その名前を束縛する識別子へのマップ
+	Warnings	: モジュールのソースコードをパースした時の警告
+ */
+type Module struct {
+	Path     string
+	Program  *ast.Program
+	Imports  map[string]*Module
+	Exports  map[string]*ast.Identifier
+	Warnings []string
+}
+
+/*
+	モジュールを読み込む構造体型.
+	SearchPath	: 相対パス（"./" や "../" で始まるもの）以外の import を探すディレクトリ
+	modules		: 絶対パスから, 読み込み済みのモジュールへのキャッシュ
+	loading		: 読み込み中のモジュールの絶対パス. import の循環を検出するために使う
+	root		: 最初に読み込んだファイルのディレクトリ. エラーメッセージのパスを短くするために使う
+ */
+type Loader struct {
+	SearchPath []string
+
+	modules map[string]*Module
+	loading []string
+	root    string
+}
+
+/*
+	import の探索パスを受け取ってローダーのインスタンスを生成する関数.
+ */
+func New(searchPath ...string) *Loader {
+	return &Loader{
+		SearchPath: searchPath,
+		modules:    map[string]*Module{},
+	}
+}
+
+/*
+	import の循環を表すエラー.
+	Chain	: 循環しているモジュールのパス. 最初と最後の要素は同じモジュール
+ */
+type CycleError struct {
+	Chain []string
+}
+
+func (e *CycleError) Error() string {
+	return "import cycle: " + strings.Join(e.Chain, " -> ")
+}
+
+/*
+	モジュールの構文解析に失敗したことを表すエラー.
+ */
+type ParseError struct {
+	Path   string
+	Errors []string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: parser errors:\n\t%s", e.Path, strings.Join(e.Errors, "\n\t"))
+}
+
+/*
+	path にあるファイルを, それが import するモジュールも含めて読み込むメソッド.
+	一度読み込んだモジュールはキャッシュされ, 同じファイルを何度 import しても同じ *Module を返す.
+ */
+func (l *Loader) Load(path string) (*Module, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if l.root == "" {
+		l.root = filepath.Dir(abs)
+	}
+	return l.load(abs)
+}
+
+/*
+	絶対パス path のモジュールを読み込むメソッド.
+ */
+func (l *Loader) load(path string) (*Module, error) {
+	if mod, ok := l.modules[path]; ok {
+		return mod, nil
+	}
+
+	for i, loading := range l.loading {
+		if loading == path {
+			chain := []string{}
+			for _, p := range l.loading[i:] {
+				chain = append(chain, l.DisplayPath(p))
+			}
+			chain = append(chain, l.DisplayPath(path))
+			return nil, &CycleError{Chain: chain}
+		}
+	}
+
+	l.loading = append(l.loading, path)
+	defer func() { l.loading = l.loading[:len(l.loading)-1] }()
+
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{Path: l.DisplayPath(path), Errors: p.Errors()}
+	}
+
+	mod := &Module{
+		Path:     path,
+		Program:  program,
+		Imports:  map[string]*Module{},
+		Exports:  map[string]*ast.Identifier{},
+		Warnings: p.Warnings(),
+	}
+
+	aliases := map[*ast.Identifier]*Module{}
+	for _, stmt := range program.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.ImportStatement:
+			resolved, err := l.resolve(path, stmt.Path)
+			if err != nil {
+				return nil, l.errorf(path, stmt.Token, "%s", err)
+			}
+			imported, err := l.load(resolved)
+			if err != nil {
+				return nil, err
+			}
+			mod.Imports[stmt.Alias.Value] = imported
+			aliases[stmt.Alias] = imported
+		case *ast.ExportStatement:
+			// 同じ名前の import や export は, パーサーが再宣言のエラーにする.
+			for _, ident := range letIdentifiers(stmt.Statement) {
+				mod.Exports[ident.Value] = ident
+			}
+		}
+	}
+
+	if err := l.checkMembers(mod, aliases); err != nil {
+		return nil, err
+	}
+
+	l.modules[path] = mod
+	return mod, nil
+}
+
+/*
+	import 文のパスを, 読み込むファイルの絶対パスに解決するメソッド.
+	"./" や "../" で始まるパスは, import しているファイルのディレクトリからの相対パスとして解決する.
+	それ以外のパスは, import しているファイルのディレクトリ, SearchPath の順に探す.
+	どちらも, ファイルが見つからなければエラーを返す.
+ */
+func (l *Loader) resolve(importer string, path string) (string, error) {
+	if filepath.Ext(path) == "" {
+		path += Extension
+	}
+
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+
+	dir := filepath.Dir(importer)
+	dirs := append([]string{dir}, l.SearchPath...)
+	if strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") {
+		dirs = []string{dir}
+	}
+
+	for _, d := range dirs {
+		candidate, err := filepath.Abs(filepath.Join(d, path))
+		if err != nil {
+			return "", err
+		}
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("cannot find module %q (searched %s)", path, strings.Join(dirs, ", "))
+}
+
+/*
+	モジュール内の <alias>.<name> というメンバーアクセスが,
+	import したモジュールが公開している名前を参照しているか調べるメソッド.
+	aliases は import 文のエイリアスの識別子から, 読み込んだモジュールへのマップ.
+	関数の仮引数などがエイリアスと同じ名前を覆い隠していることがあるので, 名前解決して
+	<alias> が import 文のエイリアスを参照している場合だけを調べる.
+ */
+func (l *Loader) checkMembers(mod *Module, aliases map[*ast.Identifier]*Module) error {
+	info, _, _ := resolver.Resolve(mod.Program)
+
+	var err error
+	ast.Walk(mod.Program, func(node ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		member, ok := node.(*ast.MemberExpression)
+		if !ok {
+			return true
+		}
+		alias, ok := member.Object.(*ast.Identifier)
+		if !ok {
+			return true
+		}
+		binding := info.Uses[alias]
+		if binding == nil {
+			return true
+		}
+		imported, ok := aliases[binding.Decl]
+		if !ok {
+			return true
+		}
+		if _, ok := imported.Exports[member.Property.Value]; !ok {
+			err = l.errorf(mod.Path, member.Property.Token, "module %s (%s) has no exported name %s",
+				alias.Value, l.DisplayPath(imported.Path), member.Property.Value)
+		}
+		return true
+	})
+	return err
+}
+
+/*
+	ソースコード上の位置を付けたエラーを生成するメソッド.
+ */
+func (l *Loader) errorf(path string, tok token.Token, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return fmt.Errorf("%s:%d:%d: %s", l.DisplayPath(path), tok.Line, tok.Column, msg)
+}
+
+/*
+	エラーメッセージや警告に表示するためのパスを返すメソッド.
+	最初に読み込んだファイルのディレクトリからの相対パスにできれば, それを返す.
+ */
+func (l *Loader) DisplayPath(path string) string {
+	if rel, err := filepath.Rel(l.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+/*
+	let 文が束縛する識別子を返すヘルパー関数.
+ */
+func letIdentifiers(stmt *ast.LetStatement) []*ast.Identifier {
+	if stmt.Pattern == nil {
+		return []*ast.Identifier{stmt.Name}
+	}
+	return ast.PatternIdentifiers(stmt.Pattern)
+}
@@ -0,0 +1,235 @@
+package loader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/*
+	テスト用の一時ディレクトリに, パスからソースコードへのマップの通りにファイルを作る.
+ */
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "bacon-loader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, source := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.bacon":          `import "./lib/math" as math; import "util" as util; math.double(util.one);`,
+		"lib/math.bacon":      `import "../util.bacon" as u; export let double = fn(x) { x * 2 }; let hidden = 1;`,
+		"util.bacon":          `export let one = 1; export let [two, {three}] = xs;`,
+		"vendor/unused.bacon": `export let x = 1;`,
+	})
+	defer os.RemoveAll(dir)
+
+	l := New()
+	mod, err := l.Load(filepath.Join(dir, "main.bacon"))
+	if err != nil {
+		t.Fatalf("Load returned error: %s", err)
+	}
+
+	if len(mod.Program.Statements) != 3 {
+		t.Errorf("wrong number of statements. got=%d", len(mod.Program.Statements))
+	}
+
+	math, ok := mod.Imports["math"]
+	if !ok {
+		t.Fatalf("module math was not imported. got=%v", mod.Imports)
+	}
+	if math.Path != filepath.Join(dir, "lib", "math.bacon") {
+		t.Errorf("math.Path wrong. got=%s", math.Path)
+	}
+	if _, ok := math.Exports["double"]; !ok {
+		t.Errorf("math does not export double. got=%v", math.Exports)
+	}
+	if _, ok := math.Exports["hidden"]; ok {
+		t.Errorf("math exports unexported name hidden")
+	}
+
+	util := mod.Imports["util"]
+	for _, name := range []string{"one", "two", "three"} {
+		if _, ok := util.Exports[name]; !ok {
+			t.Errorf("util does not export %s. got=%v", name, util.Exports)
+		}
+	}
+
+	if math.Imports["u"] != util {
+		t.Errorf("util was loaded twice instead of being cached")
+	}
+}
+
+func TestLoadWarnings(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.bacon": "let x = 1;\nlet f = fn() { let x = 2; x };",
+	})
+	defer os.RemoveAll(dir)
+
+	mod, err := New().Load(filepath.Join(dir, "main.bacon"))
+	if err != nil {
+		t.Fatalf("Load returned error: %s", err)
+	}
+
+	expected := "x at line 2, column 20 shadows the outer x declared at line 1, column 5"
+	if len(mod.Warnings) != 1 || mod.Warnings[0] != expected {
+		t.Errorf("mod.Warnings wrong. want=[%q], got=%q", expected, mod.Warnings)
+	}
+}
+
+func TestLoadSearchPath(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app/main.bacon":       `import "strings" as s; s.upper;`,
+		"stdlib/strings.bacon": `export let upper = fn(x) { x };`,
+	})
+	defer os.RemoveAll(dir)
+
+	l := New(filepath.Join(dir, "stdlib"))
+	mod, err := l.Load(filepath.Join(dir, "app", "main.bacon"))
+	if err != nil {
+		t.Fatalf("Load returned error: %s", err)
+	}
+
+	if mod.Imports["s"].Path != filepath.Join(dir, "stdlib", "strings.bacon") {
+		t.Errorf("strings resolved to wrong path. got=%s", mod.Imports["s"].Path)
+	}
+}
+
+func TestLoadShadowedAlias(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.bacon":     "import \"lib/util\" as m;\nlet f = fn(m) { m.anything };\nlet g = fn() { m.present };",
+		"lib/util.bacon": `export let present = 1;`,
+	})
+	defer os.RemoveAll(dir)
+
+	if _, err := New().Load(filepath.Join(dir, "main.bacon")); err != nil {
+		t.Errorf("Load returned error: %s", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		files         map[string]string
+		expectedError string
+	}{
+		{
+			map[string]string{
+				"main.bacon": `import "a" as a;`,
+				"a.bacon":    `import "b" as b;`,
+				"b.bacon":    `import "a" as a;`,
+			},
+			"import cycle: a.bacon -> b.bacon -> a.bacon",
+		},
+		{
+			map[string]string{
+				"main.bacon": `import "main" as self;`,
+			},
+			"import cycle: main.bacon -> main.bacon",
+		},
+		{
+			map[string]string{
+				"main.bacon": "let x = 1;\nimport \"lib\" as lib;\nlib.missing;",
+				"lib.bacon":  `export let present = 1;`,
+			},
+			"main.bacon:3:5: module lib (lib.bacon) has no exported name missing",
+		},
+		{
+			map[string]string{
+				"main.bacon": "import \"lib\" as lib;\nlet f = fn(x) { lib.missing };",
+				"lib.bacon":  `export let present = 1;`,
+			},
+			"main.bacon:2:21: module lib (lib.bacon) has no exported name missing",
+		},
+		{
+			map[string]string{
+				"main.bacon": `import "lib" as l; import "lib" as l;`,
+				"lib.bacon":  ``,
+			},
+			"main.bacon: parser errors:\n\tl redeclared in this scope at line 1, column 36 (previous declaration at line 1, column 17)",
+		},
+		{
+			map[string]string{
+				"main.bacon": `export let x = 1; export let [x] = y;`,
+			},
+			"main.bacon: parser errors:\n\tx redeclared in this scope at line 1, column 31 (previous declaration at line 1, column 12)",
+		},
+		{
+			map[string]string{
+				"main.bacon": `import "lib" as lib;`,
+				"lib.bacon":  `let = 1;`,
+			},
+			"lib.bacon: parser errors:\n\texpected next token to be IDENT, got = instead\n\tno prefix parse function for = found",
+		},
+	}
+
+	for _, tt := range tests {
+		dir := writeFiles(t, tt.files)
+
+		_, err := New().Load(filepath.Join(dir, "main.bacon"))
+		if err == nil {
+			t.Errorf("expected error %q, got none", tt.expectedError)
+		} else if err.Error() != tt.expectedError {
+			t.Errorf("wrong error. want=%q, got=%q", tt.expectedError, err.Error())
+		}
+
+		os.RemoveAll(dir)
+	}
+}
+
+func TestLoadCycleError(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.bacon": `import "main" as self;`,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := New().Load(filepath.Join(dir, "main.bacon"))
+	cycle, ok := err.(*CycleError)
+	if !ok {
+		t.Fatalf("err is not *CycleError. got=%T (%v)", err, err)
+	}
+	if len(cycle.Chain) != 2 {
+		t.Errorf("wrong cycle chain. got=%v", cycle.Chain)
+	}
+}
+
+func TestLoadMissingModule(t *testing.T) {
+	tests := []struct {
+		source        string
+		expectedError string // 探したディレクトリの前まで
+	}{
+		{`import "nowhere" as n;`, `main.bacon:1:1: cannot find module "nowhere.bacon" (searched `},
+		{`import "./nope" as n;`, `main.bacon:1:1: cannot find module "./nope.bacon" (searched `},
+		{"let x = 1;\nimport \"../nope\" as n;", `main.bacon:2:1: cannot find module "../nope.bacon" (searched `},
+	}
+
+	for _, tt := range tests {
+		dir := writeFiles(t, map[string]string{
+			"main.bacon": tt.source,
+		})
+
+		_, err := New().Load(filepath.Join(dir, "main.bacon"))
+		if err == nil {
+			t.Errorf("source %q: expected error, got none", tt.source)
+			os.RemoveAll(dir)
+			continue
+		}
+		expected := tt.expectedError + dir + ")"
+		if err.Error() != expected {
+			t.Errorf("source %q: wrong error. want=%q, got=%q", tt.source, expected, err.Error())
+		}
+
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,497 @@
+package macro
+
+import (
+	"fmt"
+	"github.com/WTBacon/goInterpreter/ast"
+	"github.com/WTBacon/goInterpreter/token"
+)
+
+/*
+	マクロの展開がこの深さを超えたら, 再帰的なマクロとみなしてエラーにする.
+ */
+const maxExpansionDepth = 100
+
+/*
+	マクロの定義を保持する構造体型.
+	構文解析（Parser.ParseProgram）の後, 実行の前に DefineMacros と ExpandMacros に渡す.
+	REPL のように複数のプログラムを順に処理する場合は, 同じ Env を使い回すと前に定義したマクロを使える.
+	macros	: マクロの名前から, マクロリテラルへのマップ
+	gensym	: 衛生的な名前の付け替えに使う連番
+	names	: 展開したプログラムと雛形に現れる名前. 付け替えた名前がこれらと衝突しないようにするために使う
+ */
+type Env struct {
+	macros map[string]*ast.MacroLiteral
+	gensym int
+	names  map[string]bool
+}
+
+/*
+	空の Env を生成する関数.
+ */
+func NewEnv() *Env {
+	return &Env{macros: map[string]*ast.MacroLiteral{}, names: map[string]bool{}}
+}
+
+/*
+	トップレベルの let <name> = macro(...) { ... }; をマクロの定義として env に登録し,
+	program から取り除く関数.
+ */
+func DefineMacros(program *ast.Program, env *Env) {
+	statements := []ast.Statement{}
+
+	for _, stmt := range program.Statements {
+		if let, ok := stmt.(*ast.LetStatement); ok && let.Name != nil {
+			if macro, ok := let.Value.(*ast.MacroLiteral); ok {
+				env.macros[let.Name.Value] = macro
+				continue
+			}
+		}
+		statements = append(statements, stmt)
+	}
+
+	program.Statements = statements
+}
+
+/*
+	program 内のマクロ呼び出しを全て展開した新しい AST を返す関数.
+	DefineMacros で取り除かれなかった（トップレベルの let 文で束縛されていない）マクロリテラルはエラーになる.
+ */
+func ExpandMacros(program ast.Node, env *Env) (ast.Node, error) {
+	var err error
+	ast.Walk(program, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Identifier); ok {
+			env.names[ident.Value] = true
+		}
+		if macro, ok := node.(*ast.MacroLiteral); ok && err == nil {
+			err = fmt.Errorf("line %d: macro literal must be bound by a top-level let statement",
+				macro.Token.Line)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return env.expand(program, 0)
+}
+
+/*
+	node 内のマクロ呼び出しを展開するメソッド.
+	展開結果にマクロ呼び出しが含まれていれば, それも再帰的に展開する.
+ */
+func (env *Env) expand(node ast.Node, depth int) (ast.Node, error) {
+	if depth > maxExpansionDepth {
+		return nil, fmt.Errorf("macro expansion exceeded depth %d; is a macro recursive?", maxExpansionDepth)
+	}
+
+	var err error
+	expanded := ast.Modify(node, func(node ast.Node) ast.Node {
+		if err != nil {
+			return node
+		}
+
+		call, ok := node.(*ast.CallExpression)
+		if !ok {
+			return node
+		}
+		ident, ok := call.Function.(*ast.Identifier)
+		if !ok {
+			return node
+		}
+		macro, ok := env.macros[ident.Value]
+		if !ok {
+			return node
+		}
+
+		var result ast.Node
+		result, err = env.expandCall(ident.Value, macro, call)
+		if err != nil {
+			return node
+		}
+		result, err = env.expand(result, depth+1)
+		if err != nil {
+			return node
+		}
+		return result
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return expanded, nil
+}
+
+/*
+	1 つのマクロ呼び出しを展開するメソッド.
+	マクロ本体は quote(...) という 1 つの式でなければならない. quote の中身を雛形として,
+	1. 雛形が導入する束縛の名前を, ユーザーのコードと衝突しない名前に付け替え（衛生性）,
+	2. unquote(...) を展開時に評価した結果で置き換えたものを, 呼び出しの代わりに返す.
+ */
+func (env *Env) expandCall(name string, macro *ast.MacroLiteral, call *ast.CallExpression) (ast.Node, error) {
+	if len(call.NamedArguments) != 0 {
+		return nil, callError(call, "macro %s does not accept keyword arguments", name)
+	}
+	for _, arg := range call.Arguments {
+		if _, ok := arg.(*ast.SpreadExpression); ok {
+			return nil, callError(call, "macro %s does not accept spread arguments", name)
+		}
+	}
+	if len(call.Arguments) != len(macro.Parameters) {
+		return nil, callError(call, "macro %s expects %d arguments, got %d",
+			name, len(macro.Parameters), len(call.Arguments))
+	}
+
+	template, ok := quotedTemplate(macro)
+	if !ok {
+		return nil, fmt.Errorf("line %d: body of macro %s must be a single quote(...) expression",
+			macro.Token.Line, name)
+	}
+
+	args := map[string]ast.Node{}
+	for i, param := range macro.Parameters {
+		args[param.Value] = call.Arguments[i]
+	}
+
+	u := &unquoter{macro: name, args: args}
+	result := u.substitute(env.rename(template))
+	if u.err != nil {
+		return nil, u.err
+	}
+	return result, nil
+}
+
+/*
+	マクロ本体が quote(<expression>) だけからなる場合に, その <expression> を返すヘルパー関数.
+ */
+func quotedTemplate(macro *ast.MacroLiteral) (ast.Expression, bool) {
+	if macro.Body == nil || len(macro.Body.Statements) != 1 {
+		return nil, false
+	}
+
+	var exp ast.Expression
+	switch stmt := macro.Body.Statements[0].(type) {
+	case *ast.ExpressionStatement:
+		exp = stmt.Expression
+	case *ast.ReturnStatement:
+		exp = stmt.ReturnValue
+	}
+
+	if !isCallTo(exp, "quote") {
+		return nil, false
+	}
+	return exp.(*ast.CallExpression).Arguments[0], true
+}
+
+/*
+	式が, 引数を 1 つだけ取る name(...) という呼び出しか判定するヘルパー関数.
+ */
+func isCallTo(exp ast.Node, name string) bool {
+	call, ok := exp.(*ast.CallExpression)
+	if !ok || len(call.Arguments) != 1 || len(call.NamedArguments) != 0 {
+		return false
+	}
+	ident, ok := call.Function.(*ast.Identifier)
+	return ok && ident.Value == name
+}
+
+/*
+	雛形が導入する束縛（let 文, 関数の仮引数, パターン, catch の仮引数）の名前を,
+	新しい名前に付け替えた雛形を返すメソッド.
+	新しい名前は, 展開した結果を字句解析器に読み直させられるように識別子として読める文字だけで作り,
+	展開するプログラムと雛形に現れる名前と衝突しないようにする.
+	unquote(...) の中はマクロの実引数を参照するので付け替えない.
+ */
+func (env *Env) rename(template ast.Node) ast.Node {
+	ast.Walk(template, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Identifier); ok {
+			env.names[ident.Value] = true
+		}
+		return true
+	})
+
+	renames := map[string]string{}
+	for _, ident := range introducedBindings(template) {
+		if _, ok := renames[ident.Value]; !ok {
+			renames[ident.Value] = env.newName(ident.Value)
+		}
+	}
+	if len(renames) == 0 {
+		return template
+	}
+
+	originals := map[string]string{}
+	for original, renamed := range renames {
+		originals[renamed] = original
+	}
+
+	return ast.Modify(template, func(node ast.Node) ast.Node {
+		switch node := node.(type) {
+		case *ast.Identifier:
+			if renamed, ok := renames[node.Value]; ok {
+				node.Value = renamed
+				node.Token.Literal = renamed
+			}
+		case *ast.MemberExpression:
+			// メンバーの名前は束縛を参照しないので, 元に戻す.
+			restore(node.Property, originals)
+		case *ast.HashPatternEntry:
+			restore(node.Key, originals)
+		case *ast.NamedArgument:
+			restore(node.Name, originals)
+		case *ast.StructField:
+			restore(node.Name, originals)
+		case *ast.Receiver:
+			restore(node.Type, originals)
+		case *ast.FunctionLiteral:
+			if node.Name != nil {
+				restore(node.Name, originals)
+			}
+		case *ast.CallExpression:
+			if isCallTo(node, "unquote") {
+				node.Arguments[0] = ast.Modify(node.Arguments[0], func(n ast.Node) ast.Node {
+					if ident, ok := n.(*ast.Identifier); ok {
+						restore(ident, originals)
+					}
+					return n
+				}).(ast.Expression)
+			}
+		}
+		return node
+	})
+}
+
+/*
+	name を付け替えた新しい名前「<元の名前>__<連番を英小文字で表したもの>」を返すメソッド.（ex. y__a, y__b, ..., y__z, y__aa）
+	Bacon の識別子は数字を含められないので, 連番は a から z の 26 進数で表す.
+ */
+func (env *Env) newName(name string) string {
+	for {
+		env.gensym += 1
+		suffix := ""
+		for n := env.gensym; n > 0; n = (n - 1) / 26 {
+			suffix = string(rune('a'+(n-1)%26)) + suffix
+		}
+		renamed := name + "__" + suffix
+		if !env.names[renamed] {
+			env.names[renamed] = true
+			return renamed
+		}
+	}
+}
+
+/*
+	付け替えた識別子の名前を元に戻すヘルパー関数.
+ */
+func restore(ident *ast.Identifier, originals map[string]string) {
+	if original, ok := originals[ident.Value]; ok {
+		ident.Value = original
+		ident.Token.Literal = original
+	}
+}
+
+/*
+	雛形が導入する束縛の識別子を返すヘルパー関数. unquote(...) の中は含まない.
+ */
+func introducedBindings(template ast.Node) []*ast.Identifier {
+	bindings := []*ast.Identifier{}
+	ast.Walk(template, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.CallExpression:
+			return !isCallTo(node, "unquote")
+		case *ast.LetStatement:
+			if node.Name != nil {
+				bindings = append(bindings, node.Name)
+			}
+			if node.Pattern != nil {
+				bindings = append(bindings, ast.PatternIdentifiers(node.Pattern)...)
+			}
+		case *ast.ConstStatement:
+			bindings = append(bindings, node.Name)
+		case *ast.FunctionLiteral:
+			if node.Receiver != nil {
+				bindings = append(bindings, node.Receiver.Name)
+			}
+			bindings = append(bindings, node.Parameters...)
+			if node.Rest != nil {
+				bindings = append(bindings, node.Rest)
+			}
+		case *ast.MatchArm:
+			bindings = append(bindings, ast.PatternIdentifiers(node.Pattern)...)
+		case *ast.TryExpression:
+			if node.CatchParam != nil {
+				bindings = append(bindings, node.CatchParam)
+			}
+		case *ast.SelectCase:
+			if node.Value != nil {
+				bindings = append(bindings, node.Value)
+			}
+		}
+		return true
+	})
+	return bindings
+}
+
+/*
+	雛形の unquote(...) を, 展開時に評価した結果で置き換える構造体型.
+	macro	: 展開しているマクロの名前（エラーメッセージ用）
+	args	: マクロの仮引数名から, 呼び出しの実引数の AST へのマップ
+	line	: 評価している unquote(...) の行番号（エラーメッセージ用）
+	err		: 評価中に起きた最初のエラー
+ */
+type unquoter struct {
+	macro string
+	args  map[string]ast.Node
+	line  int
+	err   error
+}
+
+/*
+	node 内の unquote(...) を全て置き換えた AST を返すメソッド.
+ */
+func (u *unquoter) substitute(node ast.Node) ast.Node {
+	return ast.Modify(node, func(node ast.Node) ast.Node {
+		if u.err != nil || !isCallTo(node, "unquote") {
+			return node
+		}
+		call := node.(*ast.CallExpression)
+		u.line = call.Token.Line
+		return u.eval(call.Arguments[0])
+	})
+}
+
+/*
+	unquote の引数を展開時に評価するメソッド.
+	評価できるのは, マクロの仮引数（実引数の AST になる）, quote(...)（中身の AST になる）,
+	整数・真偽値リテラルと, それらの前置・中置演算だけである.
+ */
+func (u *unquoter) eval(exp ast.Expression) ast.Node {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		if arg, ok := u.args[exp.Value]; ok {
+			return ast.Modify(arg, func(n ast.Node) ast.Node { return n })
+		}
+
+	case *ast.CallExpression:
+		if isCallTo(exp, "quote") {
+			return u.substitute(exp.Arguments[0])
+		}
+
+	case *ast.IntegerLiteral, *ast.Boolean:
+		return exp
+
+	case *ast.PrefixExpression:
+		if result, ok := foldPrefix(exp.Operator, u.eval(exp.Right)); ok {
+			return result
+		}
+
+	case *ast.InfixExpression:
+		left := u.eval(exp.Left)
+		right := u.eval(exp.Right)
+		if u.err != nil {
+			return exp
+		}
+		if r, ok := right.(*ast.IntegerLiteral); ok && r.Value == 0 && exp.Operator == "/" {
+			u.fail(exp, "division by zero in unquote(%s) in macro %s", exp.String(), u.macro)
+			return exp
+		}
+		if result, ok := foldInfix(exp.Operator, left, right); ok {
+			return result
+		}
+	}
+
+	u.fail(exp, "unquote(%s) in macro %s cannot be evaluated at expansion time", exp.String(), u.macro)
+	return exp
+}
+
+/*
+	最初のエラーだけを記録するメソッド.
+ */
+func (u *unquoter) fail(exp ast.Expression, format string, args ...interface{}) {
+	if u.err == nil {
+		u.err = fmt.Errorf("line %d: %s", u.line, fmt.Sprintf(format, args...))
+	}
+}
+
+/*
+	前置演算子をリテラルに適用した結果のリテラルを返すヘルパー関数.
+ */
+func foldPrefix(operator string, right ast.Node) (ast.Node, bool) {
+	switch right := right.(type) {
+	case *ast.IntegerLiteral:
+		if operator == "-" {
+			return integer(-right.Value), true
+		}
+	case *ast.Boolean:
+		if operator == "!" {
+			return boolean(!right.Value), true
+		}
+	}
+	return nil, false
+}
+
+/*
+	中置演算子をリテラルに適用した結果のリテラルを返すヘルパー関数.
+ */
+func foldInfix(operator string, left, right ast.Node) (ast.Node, bool) {
+	l, lok := left.(*ast.IntegerLiteral)
+	r, rok := right.(*ast.IntegerLiteral)
+	if lok && rok {
+		switch operator {
+		case "+":
+			return integer(l.Value + r.Value), true
+		case "-":
+			return integer(l.Value - r.Value), true
+		case "*":
+			return integer(l.Value * r.Value), true
+		case "/":
+			return integer(l.Value / r.Value), true
+		case "<":
+			return boolean(l.Value < r.Value), true
+		case ">":
+			return boolean(l.Value > r.Value), true
+		case "==":
+			return boolean(l.Value == r.Value), true
+		case "!=":
+			return boolean(l.Value != r.Value), true
+		}
+		return nil, false
+	}
+
+	lb, lok := left.(*ast.Boolean)
+	rb, rok := right.(*ast.Boolean)
+	if lok && rok {
+		switch operator {
+		case "==":
+			return boolean(lb.Value == rb.Value), true
+		case "!=":
+			return boolean(lb.Value != rb.Value), true
+		}
+	}
+	return nil, false
+}
+
+/*
+	整数リテラルのノードを生成するヘルパー関数.
+	負の数は, match 式のリテラルパターンと同じく "-n" というリテラルの 1 つの IntegerLiteral にする.
+ */
+func integer(value int64) ast.Node {
+	literal := fmt.Sprintf("%d", value)
+	return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: literal}, Value: value}
+}
+
+/*
+	真偽値リテラルのノードを生成するヘルパー関数.
+ */
+func boolean(value bool) ast.Node {
+	if value {
+		return &ast.Boolean{Token: token.Token{Type: token.TRUE, Literal: "true"}, Value: true}
+	}
+	return &ast.Boolean{Token: token.Token{Type: token.FALSE, Literal: "false"}, Value: false}
+}
+
+/*
+	マクロ呼び出しの位置を付けたエラーを生成するヘルパー関数.
+ */
+func callError(call *ast.CallExpression, format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", call.Token.Line, fmt.Sprintf(format, args...))
+}
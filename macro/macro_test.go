@@ -0,0 +1,296 @@
+package macro
+
+import (
+	"github.com/WTBacon/goInterpreter/ast"
+	"github.com/WTBacon/goInterpreter/lexer"
+	"github.com/WTBacon/goInterpreter/parser"
+	"github.com/WTBacon/goInterpreter/token"
+	"testing"
+)
+
+func TestDefineMacros(t *testing.T) {
+	input := `
+	let number = 1;
+	let function = fn(x, y) { x + y };
+	let mymacro = macro(x, y) { quote(x + y); };
+	`
+
+	env := NewEnv()
+	program := testParseProgram(t, input)
+
+	DefineMacros(program, env)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("wrong number of statements. got=%d", len(program.Statements))
+	}
+
+	if _, ok := env.macros["number"]; ok {
+		t.Fatalf("number should not be defined")
+	}
+	if _, ok := env.macros["function"]; ok {
+		t.Fatalf("function should not be defined")
+	}
+
+	macro, ok := env.macros["mymacro"]
+	if !ok {
+		t.Fatalf("macro not in environment.")
+	}
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("wrong number of macro parameters. got=%d", len(macro.Parameters))
+	}
+	if macro.Parameters[0].String() != "x" || macro.Parameters[1].String() != "y" {
+		t.Fatalf("parameters wrong. got=%v", macro.Parameters)
+	}
+
+	expectedBody := "quote((x + y))"
+	if macro.Body.String() != expectedBody {
+		t.Fatalf("body is not %q. got=%q", expectedBody, macro.Body.String())
+	}
+}
+
+func TestExpandMacros(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`
+			let infixExpression = macro() { quote(1 + 2); };
+
+			infixExpression();
+			`,
+			`(1 + 2)`,
+		},
+		{
+			`
+			let reverse = macro(a, b) { quote(unquote(b) - unquote(a)); };
+
+			reverse(2 + 2, 10 - 5);
+			`,
+			`(10 - 5) - (2 + 2)`,
+		},
+		{
+			`
+			let unless = macro(condition, consequence, alternative) {
+				quote(if (!(unquote(condition))) {
+					unquote(consequence);
+				} else {
+					unquote(alternative);
+				});
+			};
+
+			unless(10 > 5, f(1), g(2));
+			`,
+			`if (!(10 > 5)) { f(1) } else { g(2) }`,
+		},
+		{
+			`
+			let constant = macro() { quote(unquote(8 + 8 * 2) + unquote(-4 < 2)); };
+
+			constant();
+			`,
+			`24 + true`,
+		},
+		{
+			`
+			let twice = macro(x) { quote(unquote(quote(unquote(x) + 1)) * 2); };
+
+			twice(a);
+			`,
+			`(a + 1) * 2`,
+		},
+		{
+			`
+			let double = macro(x) { quote(unquote(x) * 2); };
+			let quadruple = macro(x) { quote(double(double(unquote(x)))); };
+
+			quadruple(y);
+			`,
+			`(y * 2) * 2`,
+		},
+		{
+			`
+			let apply = macro(f) { quote(unquote(f)(m.x, y: 1)); };
+
+			apply(g) |> apply(h);
+			`,
+			`g(m.x, y: 1) |> h(m.x, y: 1)`,
+		},
+	}
+
+	for _, tt := range tests {
+		expected := testParseProgram(t, tt.expected)
+		program := testParseProgram(t, tt.input)
+
+		env := NewEnv()
+		DefineMacros(program, env)
+		expanded, err := ExpandMacros(program, env)
+		if err != nil {
+			t.Fatalf("ExpandMacros returned error: %s", err)
+		}
+
+		if expanded.String() != expected.String() {
+			t.Errorf("not equal. want=%q, got=%q",
+				expected.String(), expanded.String())
+		}
+	}
+}
+
+func TestExpandMacrosIsHygienic(t *testing.T) {
+	input := `
+	let swap = macro(a, b) {
+		quote(fn(tmp) {
+			let t = unquote(a) + tmp;
+			match (unquote(b)) { [x, {k: v}] => t + x + v + tmp, _ => m.t }
+		}(unquote(b)));
+	};
+
+	let tmp = 1;
+	swap(tmp, t);
+	swap(x, v);
+	`
+
+	expected := `let tmp = 1;` +
+		`fn(tmp__a) let t__b = (tmp + tmp__a);match (t) { [x__c, {k: v__d}] => (((t__b + x__c) + v__d) + tmp__a), _ => m.t }(t)` +
+		`fn(tmp__e) let t__f = (x + tmp__e);match (v) { [x__g, {k: v__h}] => (((t__f + x__g) + v__h) + tmp__e), _ => m.t }(v)`
+
+	program := testParseProgram(t, input)
+
+	env := NewEnv()
+	DefineMacros(program, env)
+	expanded, err := ExpandMacros(program, env)
+	if err != nil {
+		t.Fatalf("ExpandMacros returned error: %s", err)
+	}
+
+	if expanded.String() != expected {
+		t.Errorf("not hygienic.\nwant=%q\ngot =%q", expected, expanded.String())
+	}
+}
+
+func TestExpandMacrosDoesNotModifyDefinition(t *testing.T) {
+	input := `
+	let wrap = macro(x) { quote(fn(y) { unquote(x) + y }); };
+	wrap(1);
+	wrap(2);
+	`
+
+	program := testParseProgram(t, input)
+
+	env := NewEnv()
+	DefineMacros(program, env)
+	before := env.macros["wrap"].String()
+
+	expanded, err := ExpandMacros(program, env)
+	if err != nil {
+		t.Fatalf("ExpandMacros returned error: %s", err)
+	}
+
+	if env.macros["wrap"].String() != before {
+		t.Errorf("macro definition was modified. want=%q, got=%q", before, env.macros["wrap"].String())
+	}
+	if expanded.String() != "fn(y__a) (1 + y__a)fn(y__b) (2 + y__b)" {
+		t.Errorf("wrong expansion. got=%q", expanded.String())
+	}
+	if program.String() != "wrap(1)wrap(2)" {
+		t.Errorf("original program was modified. got=%q", program.String())
+	}
+}
+
+func TestRenamedNames(t *testing.T) {
+	input := `
+	let wrap = macro(x) { quote(fn(y) { unquote(x) + y }); };
+	let y__a = 1;
+	wrap(y__a);
+	`
+
+	program := testParseProgram(t, input)
+
+	env := NewEnv()
+	DefineMacros(program, env)
+	expanded, err := ExpandMacros(program, env)
+	if err != nil {
+		t.Fatalf("ExpandMacros returned error: %s", err)
+	}
+
+	if expanded.String() != "let y__a = 1;fn(y__b) (y__a + y__b)" {
+		t.Errorf("renamed name collides with a user name. got=%q", expanded.String())
+	}
+
+	env.gensym = 25
+	for _, expected := range []string{"y__z", "y__aa"} {
+		renamed := env.newName("y")
+		if renamed != expected {
+			t.Errorf("wrong name. want=%q, got=%q", expected, renamed)
+		}
+		tok := lexer.New(renamed).NextToken()
+		if tok.Type != token.IDENT || tok.Literal != renamed {
+			t.Errorf("%s is not lexed as one identifier. got=%s %q", renamed, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestExpandMacrosErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{
+			"let m = macro(a) { quote(a) };\nm(1, 2);",
+			"line 2: macro m expects 1 arguments, got 2",
+		},
+		{
+			"let m = macro(a) { quote(a) };\nm(...xs);",
+			"line 2: macro m does not accept spread arguments",
+		},
+		{
+			"let m = macro(a) { quote(a) };\nm(a: 1);",
+			"line 2: macro m does not accept keyword arguments",
+		},
+		{
+			"let m = macro(a) { a };\nm(1);",
+			"line 1: body of macro m must be a single quote(...) expression",
+		},
+		{
+			"let m = macro(a) {\n  quote(unquote(a + 1))\n};\nm(x);",
+			"line 2: unquote((a + 1)) in macro m cannot be evaluated at expansion time",
+		},
+		{
+			"let m = macro() { quote(unquote(1 / 0)) };\nm();",
+			"line 1: division by zero in unquote((1 / 0)) in macro m",
+		},
+		{
+			"let m = macro() { quote(m()) };\nm();",
+			"macro expansion exceeded depth 100; is a macro recursive?",
+		},
+		{
+			"let f = fn() {\n  let m = macro() { quote(1) };\n};",
+			"line 2: macro literal must be bound by a top-level let statement",
+		},
+	}
+
+	for _, tt := range tests {
+		program := testParseProgram(t, tt.input)
+
+		env := NewEnv()
+		DefineMacros(program, env)
+		_, err := ExpandMacros(program, env)
+		if err == nil {
+			t.Errorf("input %q: expected error, got none", tt.input)
+			continue
+		}
+		if err.Error() != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q", tt.input, tt.expectedError, err.Error())
+		}
+	}
+}
+
+func testParseProgram(t *testing.T, input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("input %q has parser errors: %v", input, p.Errors())
+	}
+	return program
+}
@@ -9,8 +9,13 @@ import (
 
 /*
 	挨拶をして, インタラクティブモードスタート.
+	bacon check <file>... のようにサブコマンドを指定した場合は, それを実行する.
  */
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(check(os.Args[2:], os.Stdout))
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/WTBacon/goInterpreter/ast"
 	"github.com/WTBacon/goInterpreter/lexer"
+	"github.com/WTBacon/goInterpreter/resolver"
 	"github.com/WTBacon/goInterpreter/token"
 	"strconv"
 )
@@ -14,6 +15,12 @@ import (
 	curToken 		: 現在調べているトークン
 	peekToken 		: 次に調べるトークン
 	errors			: 構文解析中のエラー
+	warnings		: 構文解析中の警告（エラーではないが, 誤りの可能性が高い記述）
+	blockDepth		: 現在パースしているブロックの深さ（トップレベルでは 0）
+	structs			: 宣言された構造体型の名前から, その宣言へのマップ
+	methods			: 構造体型の名前から, 定義されたメソッド名の集合へのマップ
+	structUses		: 構造体型の名前を参照している識別子（生成式とレシーバ）. プログラムの最後に検査する
+	structLiterals	: 構造体の値を生成する式. プログラムの最後にフィールド名を検査する
 	prefixParseFns	: 前置構文解析関数のマップ
 	infixParseFns 	: 中置構文解析関数のマップ
 }
@@ -23,6 +30,14 @@ type Parser struct {
 	curToken  token.Token
 	peekToken token.Token
 	errors    []string
+	warnings  []string
+
+	blockDepth int
+
+	structs        map[string]*ast.StructStatement
+	methods        map[string]map[string]bool
+	structUses     []*ast.Identifier
+	structLiterals []*ast.StructLiteral
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
@@ -57,8 +72,9 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
  */
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
-		l:      l,
-		errors: []string{},
+		l:        l,
+		errors:   []string{},
+		warnings: []string{},
 	}
 
 	// 前置構文解析関数の初期化
@@ -79,6 +95,17 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	// FUNCTION トークンは, FunctionLiteral ノードにパースする.
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	// MATCH トークンは, MatchExpression ノードにパースする.
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
+	// TRY トークンは, TryExpression ノードにパースする.
+	p.registerPrefix(token.TRY, p.parseTryExpression)
+	// MACRO トークンは, MacroLiteral ノードにパースする.
+	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
+	// SPAWN トークンは SpawnExpression ノードに, SELECT トークンは SelectExpression ノードにパースする.
+	p.registerPrefix(token.SPAWN, p.parseSpawnExpression)
+	p.registerPrefix(token.SELECT, p.parseSelectExpression)
+
+	p.registerPrefix(token.BACKTICK, p.parseTemplateLiteral)
 
 	// 中置構文解析関数の初期化
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -92,6 +119,16 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	// PIPE トークンは, PipeExpression ノードにパースする.
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
+	// DOT トークンは, MemberExpression ノードにパースする.
+	p.registerInfix(token.DOT, p.parseMemberExpression)
+
+	p.registerInfix(token.LBRACE, p.parseStructLiteral)
+
+	p.registerInfix(token.RANGE, p.parseRangeExpression)
+	p.registerInfix(token.RANGE_INCLUSIVE, p.parseRangeExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 
 	// 2つのトークンを読み込む.
 	// 1回目で, peekToken がセットされる.
@@ -115,6 +152,13 @@ func (p *Parser) Errors() []string {
 	return p.errors
 }
 
+/*
+	構文解析中の警告を返すヘルパーメソッド.
+ */
+func (p *Parser) Warnings() []string {
+	return p.warnings
+}
+
 /*
 	curToken と peekToken を進める Parser のヘルパーメソッド.
  */
@@ -137,19 +181,53 @@ func (p *Parser) ParseProgram() *ast.Program {
 		}
 		p.nextToken()
 	}
+
+	p.checkStructUses()
+	if len(p.errors) == 0 {
+		p.checkBindings(program)
+	}
 	return program
 }
 
+/*
+	プログラムの束縛を検査して, 以下をパーサーのエラーと警告に追加するメソッド.
+	- 同じスコープで同じ名前を 2 度束縛している（エラー. 前の束縛が const 文なら, そのことも示す）
+	- let 文や const 文が, 外側のスコープの名前を覆い隠している（警告）
+	スコープの作り方は名前解決と同じにするため, resolver パッケージで検査する.
+ */
+func (p *Parser) checkBindings(program *ast.Program) {
+	errors, warnings := resolver.CheckBindings(program)
+	for _, e := range errors {
+		p.errors = append(p.errors, e.Message)
+	}
+	for _, w := range warnings {
+		p.warnings = append(p.warnings, w.Message)
+	}
+}
+
 /*
 	文をパースするメソッド.
 	現在検査しているトークンを見て, どの文に一致するか判定する.
  */
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
+	case token.SEMICOLON:
+		// 空の文は読み飛ばす.
+		return nil
 	case token.LET:
 		return p.parseLetStatement()
+	case token.CONST:
+		return p.parseConstStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.EXPORT:
+		return p.parseExportStatement()
+	case token.STRUCT:
+		return p.parseStructStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -162,11 +240,55 @@ func (p *Parser) parseStatement() ast.Statement {
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
-	if !p.expectPeek(token.IDENT) {
+	switch {
+	case p.peekTokenIs(token.LBRACKET), p.peekTokenIs(token.LBRACE):
+		// 分割代入のパターンを格納.
+		p.nextToken()
+		stmt.Pattern = p.parsePattern()
+		if stmt.Pattern == nil || !p.checkPatternNames(stmt.Pattern) || !p.checkIrrefutable(stmt.Pattern) {
+			return nil
+		}
+	case p.expectPeek(token.IDENT):
+		// 識別子の名前を格納.
+		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+		// 型注釈を格納.
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+			stmt.Type = p.parseType()
+			if stmt.Type == nil {
+				return nil
+			}
+		}
+	default:
+		return nil
+	}
+
+	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
 
-	// 識別子の名前を格納.
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+/*
+	const 文をパースするメソッド.
+	let 文と異なり, 分割代入のパターンは使えない.
+ */
+func (p *Parser) parseConstStatement() *ast.ConstStatement {
+	stmt := &ast.ConstStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
 	if !p.expectPeek(token.ASSIGN) {
@@ -176,7 +298,8 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	p.nextToken()
 
 	stmt.Value = p.parseExpression(LOWEST)
-	if !p.curTokenIs(token.SEMICOLON) {
+
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 	return stmt
@@ -185,20 +308,165 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 /*
 	return 文をパースするメソッド.
 	ReturnStatement インスタンスを生成して, return 文が終了するまでトークンのポインタを進める.
+	return の直後で文が終わる場合（';', '}', EOF, 改行）は, 値を持たない return 文になる.
  */
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
+	if p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.RBRACE) ||
+		p.peekTokenIs(token.EOF) || p.peekEndsStatement() {
+		if p.peekTokenIs(token.SEMICOLON) {
+			p.nextToken()
+		}
+		return stmt
+	}
+
 	p.nextToken()
 
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
-	for !p.curTokenIs(token.SEMICOLON) {
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+/*
+	throw 文をパースするメソッド.
+	ThrowStatement インスタンスを生成して, throw 文が終了するまでトークンのポインタを進める.
+	return 文と異なり, 送出する値は省略できない.
+ */
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{Token: p.curToken}
+
+	if p.peekToken.AfterNewline {
+		msg := fmt.Sprintf("throw statement at line %d requires a value on the same line", stmt.Token.Line)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+	if stmt.Value == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+/*
+	import 文をパースするメソッド.（ex. import "path/to/mod" as m;）
+	import 文はトップレベルにしか書けない.
+ */
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	if !p.checkTopLevel() {
+		return nil
+	}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	stmt.Path = p.curToken.Literal
+
+	if stmt.Path == "" {
+		msg := fmt.Sprintf("import at line %d has an empty path", stmt.Token.Line)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	if !p.expectPeek(token.AS) {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Alias = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 	return stmt
 }
 
+/*
+	export 文をパースするメソッド.（ex. export let <identifier> = <expression>;）
+	export 文はトップレベルにしか書けない.
+ */
+func (p *Parser) parseExportStatement() *ast.ExportStatement {
+	stmt := &ast.ExportStatement{Token: p.curToken}
+
+	if !p.checkTopLevel() {
+		return nil
+	}
+
+	if !p.expectPeek(token.LET) {
+		return nil
+	}
+
+	stmt.Statement = p.parseLetStatement()
+	if stmt.Statement == nil {
+		return nil
+	}
+
+	return stmt
+}
+
+/*
+	curToken がトップレベルにあるか調べて, ブロックの中にあればエラーを追加して false を返すメソッド.
+ */
+func (p *Parser) checkTopLevel() bool {
+	if p.blockDepth == 0 {
+		return true
+	}
+	msg := fmt.Sprintf("%s statement at line %d is only allowed at the top level",
+		p.curToken.Literal, p.curToken.Line)
+	p.errors = append(p.errors, msg)
+	return false
+}
+
+/*
+	改行の直前にあると, そこで文を終了させるトークンタイプ.
+ */
+var statementTerminators = map[token.TokenType]bool{
+	token.IDENT:    true,
+	token.INT:      true,
+	token.TRUE:     true,
+	token.FALSE:    true,
+	token.RETURN:   true,
+	token.RPAREN:   true,
+	token.RBRACKET: true,
+	token.RBRACE:   true,
+	token.BACKTICK: true,
+}
+
+/*
+	改行の直後にあっても, 前の行の続きとして扱うトークンタイプ.
+ */
+var lineContinuations = map[token.TokenType]bool{
+	token.PIPE: true,
+	token.DOT:  true,
+}
+
+/*
+	curToken の直後で文が自動的に終了するか判定するメソッド.
+	Go の自動セミコロン挿入と同様に, peekToken との間に改行があり,
+	curToken が識別子・リテラル・')'・']'・'}' などであれば, 文はそこで終了したものとみなす.
+	ただし, peekToken が '|>' のように前の行の続きであることが明らかな場合は終了しない.
+ */
+func (p *Parser) peekEndsStatement() bool {
+	if !p.peekToken.AfterNewline || lineContinuations[p.peekToken.Type] {
+		return false
+	}
+	return statementTerminators[p.curToken.Type]
+}
+
 /*
 	後続のトークンの型をチェックして, その方が正しい場合に限って nextToken を呼ぶアサーション関数.
  */
@@ -252,7 +520,8 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	// peekToken の左結合力（peekPrecedence()）が, curTokenの右結合力（引数の precedence）より高ければ,
 	// これまで構文解析したもの（leftExp）は, 次の演算子に吸収される（infix(leftExp)）.
 	// グループ化された式をパースするとき, 演算子やリテラルが続く限り左に結合していく.（")"は LOWEST になる）
-	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+	// 改行によって文が終了する場合は, 次の行のトークンを吸収しない.
+	for !p.peekTokenIs(token.SEMICOLON) && !p.peekEndsStatement() && precedence < p.peekPrecedence() {
 		infix := p.infixParseFns[p.peekToken.Type]
 		if infix == nil {
 			return leftExp
@@ -318,12 +587,16 @@ func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 const (
 	_ int = iota
 	LOWEST
+	PIPELINE     // |>
 	EQUALS       // ==
 	LESSGREATER  // > または <
+	RANGE        // 0..10 または 0..=10
 	SUM          // +
 	PRODUCT      // *
 	PREFIX       // -X または !X
 	CALL         // myFunction(X)
+	INDEX        // xs[1] または xs[1:3]
+	FIELD        // m.name
 )
 
 /*
@@ -371,15 +644,21 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 	トークンタイプの優先順位マップ : トークンタイプとその優先順位を関連づける.
  */
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,      // =
-	token.NOT_EQ:   EQUALS,      // !=
-	token.LT:       LESSGREATER, // <
-	token.GT:       LESSGREATER, // >
-	token.PLUS:     SUM,         // +
-	token.MINUS:    SUM,         // -
-	token.SLASH:    PRODUCT,     // /
-	token.ASTERISK: PRODUCT,     // *
-	token.LPAREN:   CALL,        // )
+	token.PIPE:            PIPELINE,    // |>
+	token.EQ:              EQUALS,      // =
+	token.NOT_EQ:          EQUALS,      // !=
+	token.LT:              LESSGREATER, // <
+	token.GT:              LESSGREATER, // >
+	token.RANGE:           RANGE,       // ..
+	token.RANGE_INCLUSIVE: RANGE,       // ..=
+	token.PLUS:            SUM,         // +
+	token.MINUS:           SUM,         // -
+	token.SLASH:           PRODUCT,     // /
+	token.ASTERISK:        PRODUCT,     // *
+	token.LPAREN:          CALL,        // )
+	token.LBRACE:          CALL,        // {
+	token.LBRACKET:        INDEX,       // [
+	token.DOT:             FIELD,       // .
 }
 
 /*
@@ -471,6 +750,9 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
 
+	p.blockDepth += 1
+	defer func() { p.blockDepth -= 1 }()
+
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
@@ -491,7 +773,18 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	if !p.parseFunctionParameters(lit) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.THIN_ARROW) {
+		p.nextToken()
+		p.nextToken()
+		lit.ReturnType = p.parseType()
+		if lit.ReturnType == nil {
+			return nil
+		}
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -502,59 +795,1150 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+/*
+	関数リテラルの仮引数リストをパースして, lit の Parameters, Types, Defaults, Rest に格納するメソッド.
+	curToken が '(' のときに呼ばれ, ')' まで進める. 以下の規則に反する場合はエラーを追加して false を返す.
+	- 仮引数は識別子でなければならない
+	- 同じ名前の仮引数は宣言できない
+	- デフォルト値を持つ仮引数の後ろに, デフォルト値を持たない仮引数は置けない
+	- 残余引数（...rest）は最後に 1 つだけ置ける
+ */
+func (p *Parser) parseFunctionParameters(lit *ast.FunctionLiteral) bool {
+	lit.Parameters = []*ast.Identifier{}
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers
+		return true
 	}
 
-	p.nextToken()
+	seen := map[string]bool{}
+	for {
+		p.nextToken()
 
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+		isRest := p.curTokenIs(token.ELLIPSIS)
+		if isRest {
+			p.nextToken()
+		}
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
-		p.nextToken()
+		if !p.curTokenIs(token.IDENT) {
+			p.parameterError(p.curToken)
+			return false
+		}
 		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
+
+		// fn (p Point) ... のように, 最初の仮引数の後ろに宣言済みの構造体型の名前が続く場合はメソッドのレシーバ.
+		if len(seen) == 0 && !isRest && lit.Receiver == nil && p.isReceiverType(lit) {
+			return p.parseMethodHeader(lit, ident)
+		}
+
+		if seen[ident.Value] {
+			msg := fmt.Sprintf("duplicate parameter %s", ident.Value)
+			p.errors = append(p.errors, msg)
+			return false
+		}
+		seen[ident.Value] = true
+
+		if isRest {
+			lit.Rest = ident
+			if !p.peekTokenIs(token.RPAREN) {
+				msg := fmt.Sprintf("rest parameter ...%s must be the last parameter", ident.Value)
+				p.errors = append(p.errors, msg)
+				return false
+			}
+			break
+		}
+
+		lit.Parameters = append(lit.Parameters, ident)
+
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+			typ := p.parseType()
+			if typ == nil {
+				return false
+			}
+			if lit.Types == nil {
+				lit.Types = map[string]ast.TypeAnnotation{}
+			}
+			lit.Types[ident.Value] = typ
+		}
+
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+			if lit.Defaults == nil {
+				lit.Defaults = map[string]ast.Expression{}
+			}
+			lit.Defaults[ident.Value] = p.parseExpression(LOWEST)
+			if lit.Defaults[ident.Value] == nil {
+				return false
+			}
+		} else if len(lit.Defaults) > 0 {
+			msg := fmt.Sprintf("parameter %s without default follows parameter with default", ident.Value)
+			p.errors = append(p.errors, msg)
+			return false
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
 	}
 
+	return p.expectPeek(token.RPAREN)
+}
+
+/*
+	peekToken がメソッドのレシーバの型の名前か判定するメソッド.
+	レシーバを持てるのは fn の関数リテラルだけで, レシーバの型はメソッド定義より前で宣言されていなければならない.
+	それ以外は仮引数リストとしてパースして, 仮引数の間の ',' が抜けていればそのエラーにする.
+ */
+func (p *Parser) isReceiverType(lit *ast.FunctionLiteral) bool {
+	if lit.Token.Type != token.FUNCTION || !p.peekTokenIs(token.IDENT) {
+		return false
+	}
+	_, ok := p.structs[p.peekToken.Literal]
+	return ok
+}
+
+/*
+	メソッド定義のレシーバとメソッド名をパースして, lit の Receiver と Name に格納するメソッド.
+	curToken がレシーバ名のときに呼ばれ, メソッドの仮引数リストの ')' まで進める.
+	メソッド定義はトップレベルにしか書けず, 同じ構造体型に同じ名前のメソッドは定義できない.
+ */
+func (p *Parser) parseMethodHeader(lit *ast.FunctionLiteral, name *ast.Identifier) bool {
+	receiver := &ast.Receiver{Token: name.Token, Name: name}
+	p.nextToken()
+	receiver.Type = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	lit.Receiver = receiver
+
 	if !p.expectPeek(token.RPAREN) {
+		return false
+	}
+
+	if !p.peekTokenIs(token.IDENT) {
+		msg := fmt.Sprintf("expected method name after receiver (%s), got %s instead",
+			receiver.String(), p.peekToken.Type)
+		p.errors = append(p.errors, msg)
+		return false
+	}
+	p.nextToken()
+	lit.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LPAREN) {
+		return false
+	}
+
+	if !p.parseFunctionParameters(lit) {
+		return false
+	}
+	for _, param := range lit.Parameters {
+		if param.Value == receiver.Name.Value {
+			msg := fmt.Sprintf("duplicate parameter %s", param.Value)
+			p.errors = append(p.errors, msg)
+			return false
+		}
+	}
+
+	if p.blockDepth != 0 {
+		msg := fmt.Sprintf("method definition at line %d is only allowed at the top level", lit.Token.Line)
+		p.errors = append(p.errors, msg)
+		return false
+	}
+
+	if p.methods == nil {
+		p.methods = map[string]map[string]bool{}
+	}
+	if p.methods[receiver.Type.Value] == nil {
+		p.methods[receiver.Type.Value] = map[string]bool{}
+	}
+	if p.methods[receiver.Type.Value][lit.Name.Value] {
+		msg := fmt.Sprintf("duplicate method %s.%s at line %d",
+			receiver.Type.Value, lit.Name.Value, lit.Name.Token.Line)
+		p.errors = append(p.errors, msg)
+		return false
+	}
+	p.methods[receiver.Type.Value][lit.Name.Value] = true
+	p.structUses = append(p.structUses, receiver.Type)
+
+	return true
+}
+
+/*
+	マクロリテラルをパースするメソッド.
+	マクロの仮引数は識別子だけで, デフォルト値や残余引数は使えない.
+ */
+func (p *Parser) parseMacroLiteral() ast.Expression {
+	lit := &ast.MacroLiteral{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	params := &ast.FunctionLiteral{Token: lit.Token}
+	if !p.parseFunctionParameters(params) {
 		return nil
 	}
+	if params.Defaults != nil || params.Rest != nil {
+		msg := fmt.Sprintf("macro at line %d cannot have default or rest parameters", lit.Token.Line)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+	if params.Types != nil {
+		msg := fmt.Sprintf("macro at line %d cannot have type annotations", lit.Token.Line)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+	lit.Parameters = params.Parameters
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	lit.Body = p.parseBlockStatement()
 
-	return identifiers
+	return lit
+}
+
+/*
+	仮引数として使えないトークンに遭遇した時にエラー処理をするメソッド.
+ */
+func (p *Parser) parameterError(t token.Token) {
+	msg := fmt.Sprintf("expected parameter to be %s, got %s (%q) instead", token.IDENT, t.Type, t.Literal)
+	p.errors = append(p.errors, msg)
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
-	exp.Arguments = p.parseCallArguments()
+	if !p.parseCallArguments(exp) {
+		return nil
+	}
 	return exp
 }
 
-func (p *Parser) parseCallArguments() []ast.Expression {
-	args := []ast.Expression{}
+/*
+	関数呼び出しの実引数リストをパースして, exp の Arguments と NamedArguments に格納するメソッド.
+	curToken が '(' のときに呼ばれ, ')' まで進める.
+	位置引数（...xs による展開を含む）はキーワード引数（name: value）より前に置かなければならない.
+ */
+func (p *Parser) parseCallArguments(exp *ast.CallExpression) bool {
+	exp.Arguments = []ast.Expression{}
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return args
+		return true
 	}
 
-	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
-
-	for p.peekTokenIs(token.COMMA) {
+	seen := map[string]bool{}
+	for {
 		p.nextToken()
+
+		switch {
+		case p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON):
+			arg := &ast.NamedArgument{Token: p.curToken}
+			arg.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if seen[arg.Name.Value] {
+				msg := fmt.Sprintf("duplicate keyword argument %s", arg.Name.Value)
+				p.errors = append(p.errors, msg)
+				return false
+			}
+			seen[arg.Name.Value] = true
+			p.nextToken()
+			p.nextToken()
+			arg.Value = p.parseExpression(LOWEST)
+			if arg.Value == nil {
+				return false
+			}
+			exp.NamedArguments = append(exp.NamedArguments, arg)
+		case len(exp.NamedArguments) > 0:
+			msg := fmt.Sprintf("positional argument %s follows keyword argument", p.curToken.Literal)
+			p.errors = append(p.errors, msg)
+			return false
+		case p.curTokenIs(token.ELLIPSIS):
+			spread := &ast.SpreadExpression{Token: p.curToken}
+			p.nextToken()
+			spread.Value = p.parseExpression(LOWEST)
+			if spread.Value == nil {
+				return false
+			}
+			exp.Arguments = append(exp.Arguments, spread)
+		default:
+			arg := p.parseExpression(LOWEST)
+			if arg == nil {
+				return false
+			}
+			exp.Arguments = append(exp.Arguments, arg)
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
 	}
 
-	if !p.expectPeek(token.RPAREN) {
+	return p.expectPeek(token.RPAREN)
+}
+
+/*
+	分割代入や match 式のパターンをパースするメソッド.
+	curToken が "_" なら WildcardPattern, IDENT なら Identifier, '[' なら ArrayPattern,
+	'{' なら HashPattern, 整数や真偽値なら LiteralPattern を返す.
+ */
+func (p *Parser) parsePattern() ast.Pattern {
+	switch p.curToken.Type {
+	case token.IDENT:
+		if p.curToken.Literal == "_" {
+			return &ast.WildcardPattern{Token: p.curToken}
+		}
+		return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	case token.INT, token.TRUE, token.FALSE:
+		return p.parseLiteralPattern()
+	case token.MINUS:
+		if !p.peekTokenIs(token.INT) {
+			p.peekError(token.INT)
+			return nil
+		}
+		return p.parseLiteralPattern()
+	case token.LBRACKET:
+		return p.parseArrayPattern()
+	case token.LBRACE:
+		return p.parseHashPattern()
+	default:
+		msg := fmt.Sprintf("expected pattern, got %s instead", p.curToken.Type)
+		p.errors = append(p.errors, msg)
 		return nil
 	}
+}
+
+/*
+	配列の分割代入パターンをパースするメソッド.（ex. [a, b, ...rest]）
+	残余要素（...rest）は最後に 1 つだけ置ける.
+ */
+func (p *Parser) parseArrayPattern() ast.Pattern {
+	pattern := &ast.ArrayPattern{Token: p.curToken}
+	pattern.Elements = []ast.Pattern{}
 
-	return args
+	if p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		return pattern
+	}
+
+	for {
+		p.nextToken()
+
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			pattern.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			break
+		}
+
+		element := p.parsePattern()
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return pattern
+}
+
+/*
+	ハッシュの分割代入パターンをパースするメソッド.（ex. {name, age: years}）
+	キーだけを書いた場合は, キーと同じ名前の識別子に束縛する.
+ */
+func (p *Parser) parseHashPattern() ast.Pattern {
+	pattern := &ast.HashPattern{Token: p.curToken}
+	pattern.Entries = []*ast.HashPatternEntry{}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		entry := &ast.HashPatternEntry{
+			Key: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+		}
+
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+			entry.Value = p.parsePattern()
+			if entry.Value == nil {
+				return nil
+			}
+		} else {
+			entry.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		}
+		pattern.Entries = append(pattern.Entries, entry)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return pattern
+}
+
+/*
+	リテラルパターンをパースするメソッド.
+	負の整数（ex. -1）は, 1 つの IntegerLiteral としてパースする.
+ */
+func (p *Parser) parseLiteralPattern() ast.Pattern {
+	pattern := &ast.LiteralPattern{Token: p.curToken}
+
+	if p.curTokenIs(token.MINUS) {
+		p.nextToken()
+		p.curToken.Literal = "-" + p.curToken.Literal
+	}
+
+	switch p.curToken.Type {
+	case token.INT:
+		pattern.Value = p.parserIntegerLiteral()
+	default:
+		pattern.Value = p.parseBoolean()
+	}
+	if pattern.Value == nil {
+		return nil
+	}
+
+	return pattern
+}
+
+/*
+	let 文のパターンが, どんな値にもマッチする（リテラルパターンを含まない）か調べるメソッド.
+	リテラルパターンを含んでいればエラーを追加して false を返す.
+ */
+func (p *Parser) checkIrrefutable(pattern ast.Pattern) bool {
+	switch pattern := pattern.(type) {
+	case *ast.LiteralPattern:
+		msg := fmt.Sprintf("literal pattern %s is not allowed in let binding", pattern.String())
+		p.errors = append(p.errors, msg)
+		return false
+	case *ast.ArrayPattern:
+		for _, e := range pattern.Elements {
+			if !p.checkIrrefutable(e) {
+				return false
+			}
+		}
+	case *ast.HashPattern:
+		for _, e := range pattern.Entries {
+			if !p.checkIrrefutable(e.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+/*
+	match 式をパースするメソッド.
+	各分岐は <pattern> [if <guard>] => <expression> の形をしていて, ',' で区切る（最後の ',' は省略可能）.
+	ガードを持たない "_" や識別子の分岐はどんな値にもマッチするので,
+	それより後ろの分岐には到達できない. その場合は警告を追加する.
+ */
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Arms = []*ast.MatchArm{}
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		expression.Arms = append(expression.Arms, arm)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	if len(expression.Arms) == 0 {
+		msg := fmt.Sprintf("match expression at line %d has no arms", expression.Token.Line)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	for i, arm := range expression.Arms[:len(expression.Arms)-1] {
+		if isCatchAllArm(arm) {
+			msg := fmt.Sprintf("match arm %q matches every value; %d arm(s) after it are unreachable",
+				arm.Pattern.String(), len(expression.Arms)-i-1)
+			p.warnings = append(p.warnings, msg)
+			break
+		}
+	}
+
+	return expression
+}
+
+/*
+	match 式の 1 つの分岐をパースするメソッド.
+ */
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{Token: p.curToken}
+
+	arm.Pattern = p.parsePattern()
+	if arm.Pattern == nil || !p.checkPatternNames(arm.Pattern) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		p.nextToken()
+		arm.Guard = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+
+	p.nextToken()
+	arm.Body = p.parseExpression(LOWEST)
+	if arm.Body == nil {
+		return nil
+	}
+
+	return arm
+}
+
+/*
+	ガードを持たず, どんな値にもマッチする分岐か判定するヘルパー関数.
+ */
+func isCatchAllArm(arm *ast.MatchArm) bool {
+	if arm.Guard != nil {
+		return false
+	}
+	switch arm.Pattern.(type) {
+	case *ast.WildcardPattern, *ast.Identifier:
+		return true
+	}
+	return false
+}
+
+/*
+	パターンが束縛する名前を調べて, 同じ名前が 2 回以上現れたらエラーを追加して false を返すメソッド.
+ */
+func (p *Parser) checkPatternNames(pattern ast.Pattern) bool {
+	seen := map[string]bool{}
+	ok := true
+	for _, ident := range ast.PatternIdentifiers(pattern) {
+		if seen[ident.Value] {
+			msg := fmt.Sprintf("duplicate name %s in pattern %s", ident.Value, pattern.String())
+			p.errors = append(p.errors, msg)
+			ok = false
+		}
+		seen[ident.Value] = true
+	}
+	return ok
+}
+
+/*
+	パイプライン式をパースするメソッド.
+	curToken が '|>' のときに呼ばれ, 右側の式を PIPELINE の優先順位でパースする（左結合）.
+	右側が関数になり得ない式（リテラルや演算の結果）の場合はエラーを追加する.
+ */
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	defer untrace(trace("parsePipeExpression"))
+
+	expression := &ast.PipeExpression{Token: p.curToken, Left: left}
+
+	precedence := p.curPrecedence()
+	p.nextToken()
+	target := p.curToken
+	expression.Right = p.parseExpression(precedence)
+
+	switch expression.Right.(type) {
+	case nil:
+		return nil
+	case *ast.IntegerLiteral, *ast.Boolean, *ast.PrefixExpression, *ast.InfixExpression:
+		// 右側の式はパースに失敗した部分を含むことがあるので, String() ではなく位置で報告する.
+		msg := fmt.Sprintf("pipeline target at line %d, column %d is not callable", target.Line, target.Column)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+	if left == nil {
+		return nil
+	}
+
+	return expression
+}
+
+/*
+	try 式をパースするメソッド.
+	try ブロックの後ろには catch 節と finally 節の少なくとも一方が必要で,
+	両方ある場合は catch, finally の順に書く. catch の仮引数（ex. (e)）は省略できる.
+ */
+func (p *Parser) parseTryExpression() ast.Expression {
+	expression := &ast.TryExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Block = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			expression.CatchParam = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if !p.expectPeek(token.RPAREN) {
+				return nil
+			}
+		}
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		expression.Catch = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		expression.Finally = p.parseBlockStatement()
+	}
+
+	if expression.Catch == nil && expression.Finally == nil {
+		msg := fmt.Sprintf("try expression at line %d must have a catch or finally block", expression.Token.Line)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	return expression
+}
+
+/*
+	spawn 式をパースするメソッド.（ex. spawn worker(c, x)）
+	spawn の後ろには関数呼び出しが来なければならない.
+ */
+func (p *Parser) parseSpawnExpression() ast.Expression {
+	expression := &ast.SpawnExpression{Token: p.curToken}
+
+	p.nextToken()
+	exp := p.parseExpression(PREFIX)
+	if exp == nil {
+		return nil
+	}
+
+	call, ok := exp.(*ast.CallExpression)
+	if !ok {
+		// exp はパースに失敗した部分式を含むことがあるので, 文字列にせず位置で示す.
+		msg := fmt.Sprintf("spawn at line %d, column %d requires a function call",
+			expression.Token.Line, expression.Token.Column)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+	expression.Call = call
+
+	return expression
+}
+
+/*
+	select 式をパースするメソッド.（ex. select { recv(c) as v => v, send(d, 1) => 0, _ => -1 }）
+	各分岐は recv(c) か send(c, v) の呼び出しで, recv の分岐だけが as で受け取った値を束縛できる.
+	_ => ... はデフォルトの分岐（どのチャネル操作もすぐに実行できない時に選ばれる）で, 1 つまで書ける.
+ */
+func (p *Parser) parseSelectExpression() ast.Expression {
+	expression := &ast.SelectExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Cases = []*ast.SelectCase{}
+	hasDefault := false
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		sc := p.parseSelectCase()
+		if sc == nil {
+			return nil
+		}
+		if sc.Comm == nil {
+			if hasDefault {
+				msg := fmt.Sprintf("select expression at line %d has more than one default case",
+					expression.Token.Line)
+				p.errors = append(p.errors, msg)
+				return nil
+			}
+			hasDefault = true
+		}
+		expression.Cases = append(expression.Cases, sc)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	if len(expression.Cases) == 0 {
+		msg := fmt.Sprintf("select expression at line %d has no cases", expression.Token.Line)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	return expression
+}
+
+/*
+	select 式の 1 つの分岐をパースするメソッド.
+ */
+func (p *Parser) parseSelectCase() *ast.SelectCase {
+	sc := &ast.SelectCase{Token: p.curToken}
+
+	if !p.curTokenIs(token.IDENT) || p.curToken.Literal != "_" || !p.peekTokenIs(token.ARROW) {
+		exp := p.parseExpression(LOWEST)
+		if exp == nil {
+			return nil
+		}
+		name := channelOperation(exp)
+		if name == "" {
+			msg := fmt.Sprintf("select case at line %d, column %d must be recv(c) or send(c, v)",
+				sc.Token.Line, sc.Token.Column)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		sc.Comm = exp.(*ast.CallExpression)
+
+		if p.peekTokenIs(token.AS) {
+			p.nextToken()
+			if name != "recv" {
+				msg := fmt.Sprintf("%s case at line %d cannot bind a value", name, sc.Token.Line)
+				p.errors = append(p.errors, msg)
+				return nil
+			}
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			sc.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		}
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+
+	p.nextToken()
+	sc.Body = p.parseExpression(LOWEST)
+	if sc.Body == nil {
+		return nil
+	}
+
+	return sc
+}
+
+/*
+	式が recv(c) か send(c, v) の呼び出しなら, その関数名を返すヘルパー関数. それ以外なら "" を返す.
+ */
+func channelOperation(exp ast.Expression) string {
+	call, ok := exp.(*ast.CallExpression)
+	if !ok || len(call.NamedArguments) != 0 {
+		return ""
+	}
+	ident, ok := call.Function.(*ast.Identifier)
+	if !ok {
+		return ""
+	}
+	switch {
+	case ident.Value == "recv" && len(call.Arguments) == 1:
+		return "recv"
+	case ident.Value == "send" && len(call.Arguments) == 2:
+		return "send"
+	}
+	return ""
+}
+
+/*
+	メンバーアクセス式をパースするメソッド.（ex. m.name）
+	curToken が '.' のときに呼ばれ, '.' の後ろには識別子が来なければならない.
+ */
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	defer untrace(trace("parseMemberExpression"))
+
+	expression := &ast.MemberExpression{Token: p.curToken, Object: object}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	expression.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	return expression
+}
+
+/*
+	構造体型の宣言をパースするメソッド.（ex. struct Point { x, y }）
+	構造体型の宣言はトップレベルにしか書けず, 同じ名前の構造体型や同じ名前のフィールドは宣言できない.
+ */
+func (p *Parser) parseStructStatement() *ast.StructStatement {
+	stmt := &ast.StructStatement{Token: p.curToken}
+
+	if !p.checkTopLevel() {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if _, ok := p.structs[stmt.Name.Value]; ok {
+		msg := fmt.Sprintf("struct %s at line %d is already declared", stmt.Name.Value, stmt.Token.Line)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Fields = []*ast.Identifier{}
+	seen := map[string]bool{}
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		field := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+		if seen[field.Value] {
+			msg := fmt.Sprintf("duplicate field %s in struct %s at line %d",
+				field.Value, stmt.Name.Value, field.Token.Line)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		seen[field.Value] = true
+		stmt.Fields = append(stmt.Fields, field)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	p.DeclareStruct(stmt)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+/*
+	構造体の値を生成する式をパースするメソッド.（ex. Point { x: 1, y: 2 }）
+	curToken が '{' のときに呼ばれ, '{' の前には構造体型の名前が来なければならない.
+	構造体型は後ろで宣言されていてもよいので, 型の名前とフィールド名はプログラムの最後に検査する.
+ */
+func (p *Parser) parseStructLiteral(name ast.Expression) ast.Expression {
+	defer untrace(trace("parseStructLiteral"))
+
+	if name == nil {
+		return nil
+	}
+
+	ident, ok := name.(*ast.Identifier)
+	if !ok {
+		msg := fmt.Sprintf("expected struct name before { at line %d, column %d",
+			p.curToken.Line, p.curToken.Column)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	expression := &ast.StructLiteral{Token: p.curToken, Name: ident}
+
+	expression.Fields = []*ast.StructField{}
+	seen := map[string]bool{}
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		field := &ast.StructField{Token: p.curToken}
+		field.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+		if seen[field.Name.Value] {
+			msg := fmt.Sprintf("duplicate field %s in %s literal at line %d",
+				field.Name.Value, ident.Value, field.Token.Line)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		seen[field.Name.Value] = true
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		field.Value = p.parseExpression(LOWEST)
+		if field.Value == nil {
+			return nil
+		}
+		expression.Fields = append(expression.Fields, field)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	p.structUses = append(p.structUses, ident)
+	p.structLiterals = append(p.structLiterals, expression)
+	return expression
+}
+
+/*
+	構造体型の宣言を登録するメソッド.
+	REPL のように複数のプログラムを順にパースする場合に, 前のプログラムで宣言した構造体型を引き継ぐために使う.
+ */
+func (p *Parser) DeclareStruct(stmt *ast.StructStatement) {
+	if p.structs == nil {
+		p.structs = map[string]*ast.StructStatement{}
+	}
+	p.structs[stmt.Name.Value] = stmt
+}
+
+/*
+	宣言された構造体型を返すメソッド.
+ */
+func (p *Parser) Structs() map[string]*ast.StructStatement {
+	return p.structs
+}
+
+/*
+	構造体型の名前を参照している箇所が宣言済みの構造体型を指しているか,
+	構造体の値を生成する式のフィールド名がその構造体型で宣言されているかを検査するメソッド.
+ */
+func (p *Parser) checkStructUses() {
+	for _, ident := range p.structUses {
+		if _, ok := p.structs[ident.Value]; !ok {
+			msg := fmt.Sprintf("unknown struct %s at line %d", ident.Value, ident.Token.Line)
+			p.errors = append(p.errors, msg)
+		}
+	}
+
+	for _, lit := range p.structLiterals {
+		stmt, ok := p.structs[lit.Name.Value]
+		if !ok {
+			continue
+		}
+		for _, field := range lit.Fields {
+			if !hasField(stmt, field.Name.Value) {
+				msg := fmt.Sprintf("struct %s has no field %s (line %d)",
+					stmt.Name.Value, field.Name.Value, field.Token.Line)
+				p.errors = append(p.errors, msg)
+			}
+		}
+	}
+}
+
+/*
+	構造体型が name という名前のフィールドを持つか判定するヘルパー関数.
+ */
+func hasField(stmt *ast.StructStatement, name string) bool {
+	for _, f := range stmt.Fields {
+		if f.Value == name {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	テンプレート文字列をパースするメソッド.（ex. `Hello, ${name}!`）
+	curToken が開始の '`' のときに呼ばれ, 閉じる '`' まで進める.
+	"${" と "}" の間の埋め込み式は parseExpression でパースする.
+ */
+func (p *Parser) parseTemplateLiteral() ast.Expression {
+	defer untrace(trace("parseTemplateLiteral"))
+
+	lit := &ast.TemplateLiteral{Token: p.curToken}
+	lit.Parts = []ast.Expression{}
+
+	for !p.peekTokenIs(token.BACKTICK) {
+		p.nextToken()
+
+		switch p.curToken.Type {
+		case token.TEMPLATE_TEXT:
+			lit.Parts = append(lit.Parts, &ast.TemplateText{Token: p.curToken, Value: p.curToken.Literal})
+		case token.INTERPOLATION:
+			if p.peekTokenIs(token.RBRACE) {
+				msg := fmt.Sprintf("empty interpolation in template literal at line %d", p.curToken.Line)
+				p.errors = append(p.errors, msg)
+				return nil
+			}
+			p.nextToken()
+			lit.Parts = append(lit.Parts, p.parseExpression(LOWEST))
+			if !p.expectPeek(token.RBRACE) {
+				return nil
+			}
+		default:
+			msg := fmt.Sprintf("unterminated template literal starting at line %d", lit.Token.Line)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+	}
+	p.nextToken()
+
+	return lit
+}
+
+/*
+	範囲式をパースするメソッド.（ex. 0..10 / a..=b）
+	範囲式は連結できない（ex. a..b..c はエラー）.
+ */
+func (p *Parser) parseRangeExpression(start ast.Expression) ast.Expression {
+	defer untrace(trace("parseRangeExpression"))
+
+	expression := &ast.RangeExpression{
+		Token:     p.curToken,
+		Start:     start,
+		Inclusive: p.curTokenIs(token.RANGE_INCLUSIVE),
+	}
+
+	if _, ok := start.(*ast.RangeExpression); ok {
+		msg := fmt.Sprintf("range expression at line %d cannot be chained", expression.Token.Line)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	p.nextToken()
+	expression.End = p.parseExpression(RANGE)
+
+	return expression
+}
+
+/*
+	添字式とスライス式をパースするメソッド.（ex. xs[1] / xs[1:3] / s[:n] / xs[::-1]）
+	curToken が '[' のときに呼ばれ, ']' まで進める.
+	':' を含まなければ IndexExpression, 含めば SliceExpression を返す.
+ */
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	defer untrace(trace("parseIndexExpression"))
+
+	tok := p.curToken
+
+	// 添字を ':' で区切った, 始まり・終わり・増分. 省略された添字は nil.
+	bounds := []ast.Expression{nil}
+	p.nextToken()
+	for {
+		if !p.curTokenIs(token.COLON) && !p.curTokenIs(token.RBRACKET) {
+			bounds[len(bounds)-1] = p.parseExpression(LOWEST)
+			if !p.peekTokenIs(token.COLON) && !p.peekTokenIs(token.RBRACKET) {
+				p.peekError(token.RBRACKET)
+				return nil
+			}
+			p.nextToken()
+		}
+
+		if p.curTokenIs(token.RBRACKET) {
+			break
+		}
+
+		if len(bounds) == 3 {
+			msg := fmt.Sprintf("slice at line %d has too many colons", tok.Line)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		bounds = append(bounds, nil)
+		p.nextToken()
+	}
+
+	if len(bounds) == 1 {
+		if bounds[0] == nil {
+			msg := fmt.Sprintf("missing index at line %d", tok.Line)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		return &ast.IndexExpression{Token: tok, Left: left, Index: bounds[0]}
+	}
+
+	slice := &ast.SliceExpression{Token: tok, Left: left, Start: bounds[0], End: bounds[1]}
+	if len(bounds) == 3 {
+		slice.Step = bounds[2]
+	}
+	return slice
+}
+
+/*
+	型注釈をパースするメソッド.（ex. int / Point / fn(int, int) -> int）
+	curToken が型注釈の最初のトークンのときに呼ばれ, 型注釈の最後のトークンまで進める.
+ */
+func (p *Parser) parseType() ast.TypeAnnotation {
+	switch p.curToken.Type {
+	case token.IDENT:
+		return &ast.NamedType{Token: p.curToken, Name: p.curToken.Literal}
+	case token.FUNCTION:
+		typ := &ast.FunctionType{Token: p.curToken}
+
+		if !p.expectPeek(token.LPAREN) {
+			return nil
+		}
+
+		typ.Parameters = []ast.TypeAnnotation{}
+		for !p.peekTokenIs(token.RPAREN) {
+			p.nextToken()
+			param := p.parseType()
+			if param == nil {
+				return nil
+			}
+			typ.Parameters = append(typ.Parameters, param)
+
+			if !p.peekTokenIs(token.RPAREN) && !p.expectPeek(token.COMMA) {
+				return nil
+			}
+		}
+		p.nextToken()
+
+		if p.peekTokenIs(token.THIN_ARROW) {
+			p.nextToken()
+			p.nextToken()
+			typ.Return = p.parseType()
+			if typ.Return == nil {
+				return nil
+			}
+		}
+		return typ
+	}
+
+	msg := fmt.Sprintf("expected type at line %d, got %s (%q) instead",
+		p.curToken.Line, p.curToken.Type, p.curToken.Literal)
+	p.errors = append(p.errors, msg)
+	return nil
 }
@@ -391,6 +391,46 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a + b + c * d / f + g)",
 			"add((((a + b) + ((c * d) / f)) + g))",
 		},
+		{
+			"xs |> filter(f) |> map(g) |> sum",
+			"(((xs |> filter(f)) |> map(g)) |> sum)",
+		},
+		{
+			"a + b * c |> f",
+			"((a + (b * c)) |> f)",
+		},
+		{
+			"x |> fn(y) { y * 2 }",
+			"(x |> fn(y) (y * 2))",
+		},
+		{
+			"-m.x * m.f(a.b).c",
+			"((-m.x) * m.f(a.b).c)",
+		},
+		{
+			"struct P { x }; P { x: 1 + 2 }.x * 3",
+			"struct P { x }(P { x: (1 + 2) }.x * 3)",
+		},
+		{
+			"0..n + 1",
+			"(0..(n + 1))",
+		},
+		{
+			"a..=b == c..d",
+			"((a..=b) == (c..d))",
+		},
+		{
+			"-xs[1] * m.ys[i + 1:][0]",
+			"((-(xs[1])) * ((m.ys[(i + 1):])[0]))",
+		},
+		{
+			"f(a)[::-1]",
+			"(f(a)[::(-1)])",
+		},
+		{
+			"xs[1..3] |> f",
+			"((xs[(1..3)]) |> f)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -830,3 +870,1483 @@ func TestCallExpressionParameterParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestFunctionParameterDefaultsAndRest(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedParams   []string
+		expectedDefaults map[string]string
+		expectedRest     string
+		expectedString   string
+	}{
+		{
+			input:            "fn(x, y = 10) {};",
+			expectedParams:   []string{"x", "y"},
+			expectedDefaults: map[string]string{"y": "10"},
+			expectedString:   "fn(x, y = 10) ",
+		},
+		{
+			input:            "fn(first, ...rest) {};",
+			expectedParams:   []string{"first"},
+			expectedDefaults: map[string]string{},
+			expectedRest:     "rest",
+			expectedString:   "fn(first, ...rest) ",
+		},
+		{
+			input:            "fn(a, b = 1 + 2, c = a, ...others) {};",
+			expectedParams:   []string{"a", "b", "c"},
+			expectedDefaults: map[string]string{"b": "(1 + 2)", "c": "a"},
+			expectedRest:     "others",
+			expectedString:   "fn(a, b = (1 + 2), c = a, ...others) ",
+		},
+		{
+			input:            "fn(...all) {};",
+			expectedParams:   []string{},
+			expectedDefaults: map[string]string{},
+			expectedRest:     "all",
+			expectedString:   "fn(...all) ",
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Fatalf("length parameters wrong. want %d, got=%d\n",
+				len(tt.expectedParams), len(function.Parameters))
+		}
+		for i, ident := range tt.expectedParams {
+			testLiteralExpression(t, function.Parameters[i], ident)
+		}
+
+		if len(function.Defaults) != len(tt.expectedDefaults) {
+			t.Errorf("length defaults wrong. want %d, got=%d\n",
+				len(tt.expectedDefaults), len(function.Defaults))
+		}
+		for name, def := range tt.expectedDefaults {
+			if function.Defaults[name].String() != def {
+				t.Errorf("default of %s wrong. want=%q, got=%q",
+					name, def, function.Defaults[name].String())
+			}
+		}
+
+		if tt.expectedRest == "" {
+			if function.Rest != nil {
+				t.Errorf("function.Rest was not nil. got=%s", function.Rest)
+			}
+		} else {
+			testIdentifier(t, function.Rest, tt.expectedRest)
+		}
+
+		if function.String() != tt.expectedString {
+			t.Errorf("function.String() wrong. want=%q, got=%q",
+				tt.expectedString, function.String())
+		}
+	}
+}
+
+func TestInvalidFunctionParameters(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"fn(1, +) {};", `expected parameter to be IDENT, got INT ("1") instead`},
+		{"fn(x, +) {};", `expected parameter to be IDENT, got + ("+") instead`},
+		{"fn(x, x) {};", "duplicate parameter x"},
+		{"fn(...rest, x) {};", "rest parameter ...rest must be the last parameter"},
+		{"fn(x = 1, y) {};", "parameter y without default follows parameter with default"},
+		{"fn(x y) {};", "expected next token to be ), got IDENT instead"},
+		{"struct P { x }\nfn(x P) {};", "expected method name after receiver (x P), got { instead"},
+		{"fn(x y z) {};", "expected next token to be ), got IDENT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestCallExpressionSpreadAndKeywordArguments(t *testing.T) {
+	input := "f(1, ...xs, y: 2, z: a + b);"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T",
+			stmt.Expression)
+	}
+
+	if len(exp.Arguments) != 2 {
+		t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+	}
+	testLiteralExpression(t, exp.Arguments[0], 1)
+
+	spread, ok := exp.Arguments[1].(*ast.SpreadExpression)
+	if !ok {
+		t.Fatalf("exp.Arguments[1] is not ast.SpreadExpression. got=%T",
+			exp.Arguments[1])
+	}
+	testIdentifier(t, spread.Value, "xs")
+
+	if len(exp.NamedArguments) != 2 {
+		t.Fatalf("wrong length of named arguments. got=%d", len(exp.NamedArguments))
+	}
+	testIdentifier(t, exp.NamedArguments[0].Name, "y")
+	testLiteralExpression(t, exp.NamedArguments[0].Value, 2)
+	testIdentifier(t, exp.NamedArguments[1].Name, "z")
+	testInfixExpression(t, exp.NamedArguments[1].Value, "a", "+", "b")
+
+	expected := "f(1, ...xs, y: 2, z: (a + b))"
+	if exp.String() != expected {
+		t.Errorf("exp.String() wrong. want=%q, got=%q", expected, exp.String())
+	}
+}
+
+func TestInvalidCallArguments(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"f(y: 1, 2);", "positional argument 2 follows keyword argument"},
+		{"f(y: 1, y: 2);", "duplicate keyword argument y"},
+		{"f(+)", "no prefix parse function for + found"},
+		{"f(1, +) + 1", "no prefix parse function for + found"},
+		{"f(y: +)", "no prefix parse function for + found"},
+		{"f(...+)", "no prefix parse function for + found"},
+		{"g(f(,))", "no prefix parse function for , found"},
+		{"fn(x = +) { x }", "no prefix parse function for + found"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		// 引数のパースに失敗した呼び出し式が AST に残っていると, String() が panic する.
+		_ = program.String()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedNames []string
+		expected      string
+	}{
+		{"let [a, b, ...rest] = xs;", []string{"a", "b", "rest"}, "let [a, b, ...rest] = xs;"},
+		{"let [] = xs;", []string{}, "let [] = xs;"},
+		{"let [...all] = xs;", []string{"all"}, "let [...all] = xs;"},
+		{"let {name, age: years} = person;", []string{"name", "years"}, "let {name, age: years} = person;"},
+		{"let {} = person;", []string{}, "let {} = person;"},
+		{"let [first, {pos: [x, y]}] = items;", []string{"first", "x", "y"}, "let [first, {pos: [x, y]}] = items;"},
+		{"let {a: [b, ...c], d} = h;", []string{"b", "c", "d"}, "let {a: [b, ...c], d} = h;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.LetStatement. got=%T",
+				program.Statements[0])
+		}
+		if stmt.Name != nil {
+			t.Errorf("stmt.Name was not nil. got=%s", stmt.Name)
+		}
+		if stmt.Pattern == nil {
+			t.Fatalf("stmt.Pattern is nil")
+		}
+
+		names := ast.PatternIdentifiers(stmt.Pattern)
+		if len(names) != len(tt.expectedNames) {
+			t.Fatalf("wrong number of bound names. want=%d, got=%d",
+				len(tt.expectedNames), len(names))
+		}
+		for i, name := range tt.expectedNames {
+			testIdentifier(t, names[i], name)
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("program.String() wrong. want=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"let [a, a] = xs;", "duplicate name a in pattern [a, a]"},
+		{"let [a, ...a] = xs;", "duplicate name a in pattern [a, ...a]"},
+		{"let {a, b: a} = h;", "duplicate name a in pattern {a, b: a}"},
+		{"let [x, {y: x}] = xs;", "duplicate name x in pattern [x, {y: x}]"},
+		{"let [...rest, a] = xs;", "expected next token to be ], got , instead"},
+		{"let [1] = xs;", "literal pattern 1 is not allowed in let binding"},
+		{"let [a, +] = xs;", "expected pattern, got + instead"},
+		{"let {1} = h;", "expected next token to be IDENT, got INT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `match (x) {
+		0 => a,
+		-1 => b,
+		true => c,
+		[first, ...rest] if first > 0 => first,
+		{name, age: years} => years,
+		n if n < 10 => n * 2,
+		_ => 42,
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(p.Warnings()) != 0 {
+		t.Errorf("expected no warnings, got %v", p.Warnings())
+	}
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+			len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MatchExpression. got=%T",
+			stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Subject, "x") {
+		return
+	}
+
+	tests := []struct {
+		expectedPatternType string
+		expectedPattern     string
+		expectedGuard       string
+		expectedBody        string
+	}{
+		{"*ast.LiteralPattern", "0", "", "a"},
+		{"*ast.LiteralPattern", "-1", "", "b"},
+		{"*ast.LiteralPattern", "true", "", "c"},
+		{"*ast.ArrayPattern", "[first, ...rest]", "(first > 0)", "first"},
+		{"*ast.HashPattern", "{name, age: years}", "", "years"},
+		{"*ast.Identifier", "n", "(n < 10)", "(n * 2)"},
+		{"*ast.WildcardPattern", "_", "", "42"},
+	}
+
+	if len(exp.Arms) != len(tests) {
+		t.Fatalf("wrong number of arms. want=%d, got=%d", len(tests), len(exp.Arms))
+	}
+
+	for i, tt := range tests {
+		arm := exp.Arms[i]
+		if fmt.Sprintf("%T", arm.Pattern) != tt.expectedPatternType {
+			t.Errorf("arm %d: pattern type wrong. want=%s, got=%T",
+				i, tt.expectedPatternType, arm.Pattern)
+		}
+		if arm.Pattern.String() != tt.expectedPattern {
+			t.Errorf("arm %d: pattern wrong. want=%q, got=%q",
+				i, tt.expectedPattern, arm.Pattern.String())
+		}
+		guard := ""
+		if arm.Guard != nil {
+			guard = arm.Guard.String()
+		}
+		if guard != tt.expectedGuard {
+			t.Errorf("arm %d: guard wrong. want=%q, got=%q", i, tt.expectedGuard, guard)
+		}
+		if arm.Body.String() != tt.expectedBody {
+			t.Errorf("arm %d: body wrong. want=%q, got=%q",
+				i, tt.expectedBody, arm.Body.String())
+		}
+	}
+
+	lit := exp.Arms[1].Pattern.(*ast.LiteralPattern)
+	testIntegerLiteral(t, lit.Value, -1)
+}
+
+func TestMatchExpressionString(t *testing.T) {
+	input := "let y = match (f(x)) { 1 => true, _ if x => false };"
+	expected := "let y = match (f(x)) { 1 => true, _ if x => false };"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if program.String() != expected {
+		t.Errorf("program.String() wrong. want=%q, got=%q", expected, program.String())
+	}
+}
+
+func TestMatchExpressionUnreachableArmWarnings(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedWarnings []string
+	}{
+		{"match (x) { 1 => a, _ => b }", []string{}},
+		{"match (x) { _ if x => a, 1 => b }", []string{}},
+		{
+			"match (x) { _ => a, 1 => b, 2 => c }",
+			[]string{`match arm "_" matches every value; 2 arm(s) after it are unreachable`},
+		},
+		{
+			"match (x) { 1 => a, n => n, _ => b }",
+			[]string{`match arm "n" matches every value; 1 arm(s) after it are unreachable`},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+		checkParserErrors(t, p)
+
+		warnings := p.Warnings()
+		if len(warnings) != len(tt.expectedWarnings) {
+			t.Errorf("input %q: wrong number of warnings. want=%v, got=%v",
+				tt.input, tt.expectedWarnings, warnings)
+			continue
+		}
+		for i, w := range tt.expectedWarnings {
+			if warnings[i] != w {
+				t.Errorf("input %q: wrong warning. want=%q, got=%q", tt.input, w, warnings[i])
+			}
+		}
+	}
+}
+
+func TestInvalidMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"match (x) { }", "match expression at line 1 has no arms"},
+		{"match (f(+)) {}", "no prefix parse function for + found"},
+		{"match (fn() { let = 1 }) {}", "expected next token to be IDENT, got = instead"},
+		{"match (x) { 1 a }", "expected next token to be =>, got IDENT instead"},
+		{"match (x) { 1 => a 2 => b }", "expected next token to be ,, got INT instead"},
+		{"match (x) { [a, a] => a }", "duplicate name a in pattern [a, a]"},
+		{"match (x) { - a => a }", "expected next token to be INT, got IDENT instead"},
+		{"match x { _ => a }", "expected next token to be (, got IDENT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		_ = program.String()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestPipeExpression(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedCall string
+	}{
+		{"xs |> sum", "sum(xs)"},
+		{"xs |> filter(f)", "filter(xs, f)"},
+		{"xs |> reduce(f, init: 0)", "reduce(xs, f, init: 0)"},
+		{"xs |> filter(f) |> map(g) |> sum", "sum(((xs |> filter(f)) |> map(g)))"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.PipeExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.PipeExpression. got=%T",
+				stmt.Expression)
+		}
+
+		if exp.Call().String() != tt.expectedCall {
+			t.Errorf("exp.Call() wrong. want=%q, got=%q", tt.expectedCall, exp.Call().String())
+		}
+	}
+}
+
+func TestInvalidPipeExpressions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"xs |> 1", "pipeline target at line 1, column 7 is not callable"},
+		{"xs |> true", "pipeline target at line 1, column 7 is not callable"},
+		{"xs |> -f", "pipeline target at line 1, column 7 is not callable"},
+		{"x |> f(+) + 1", "no prefix parse function for + found"},
+		{"xs |> ;", "no prefix parse function for ; found"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		_ = program.String()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestOptionalSemicolons(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedStatements int
+		expected           string
+	}{
+		{"return 5", 1, "return 5;"},
+		{"return", 1, "return;"},
+		{"return\nx", 2, "return;x"},
+		{"let x = 5\nlet y = x", 2, "let x = 5;let y = x;"},
+		{"let x = 5 let y = x", 2, "let x = 5;let y = x;"},
+		{"a\n-b", 2, "a(-b)"},
+		{"a -\nb", 1, "(a - b)"},
+		{"f\n(x)", 2, "fx"},
+		{"xs\n|> filter(f)\n|> sum", 1, "((xs |> filter(f)) |> sum)"},
+		{"fn(x) {\n  return x\n}\n(5)", 2, "fn(x) return x;5"},
+		{"if (x) {\n  a\n}\nelse {\n  b\n}", 1, "ifx aelse b"},
+		{"let f = fn(a, b) {\n  a\n  b\n}\nf(1, 2)", 2, "let f = fn(a, b) ab;f(1, 2)"},
+		{";;x;;", 1, "x"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != tt.expectedStatements {
+			t.Errorf("input %q: wrong number of statements. want=%d, got=%d",
+				tt.input, tt.expectedStatements, len(program.Statements))
+		}
+		if program.String() != tt.expected {
+			t.Errorf("input %q: program.String() wrong. want=%q, got=%q",
+				tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestThrowStatement(t *testing.T) {
+	input := "throw x + 1;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+			len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ThrowStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ThrowStatement. got=%T",
+			program.Statements[0])
+	}
+	testInfixExpression(t, stmt.Value, "x", "+", 1)
+
+	if program.String() != "throw (x + 1);" {
+		t.Errorf("program.String() wrong. got=%q", program.String())
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedCatchParam string
+		hasCatch           bool
+		hasFinally         bool
+		expected           string
+	}{
+		{"try { f() } catch (e) { e }", "e", true, false, "try f() catch(e) e"},
+		{"try { f() } finally { g() }", "", false, true, "try f() finally g()"},
+		{"try { f() } catch { 0 } finally { g() }", "", true, true, "try f() catch 0 finally g()"},
+		{"try {\n  f()\n}\ncatch (err) {\n  err\n}\nfinally {\n  g()\n}", "err", true, true,
+			"try f() catch(err) err finally g()"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements))
+		}
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.TryExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.TryExpression. got=%T",
+				stmt.Expression)
+		}
+
+		if tt.expectedCatchParam == "" {
+			if exp.CatchParam != nil {
+				t.Errorf("exp.CatchParam was not nil. got=%s", exp.CatchParam)
+			}
+		} else {
+			testIdentifier(t, exp.CatchParam, tt.expectedCatchParam)
+		}
+		if (exp.Catch != nil) != tt.hasCatch {
+			t.Errorf("exp.Catch wrong. want present=%t, got=%+v", tt.hasCatch, exp.Catch)
+		}
+		if (exp.Finally != nil) != tt.hasFinally {
+			t.Errorf("exp.Finally wrong. want present=%t, got=%+v", tt.hasFinally, exp.Finally)
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("program.String() wrong. want=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidTryAndThrow(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"try { f() }", "try expression at line 1 must have a catch or finally block"},
+		{"let x = 1\ntry { f() } g()", "try expression at line 2 must have a catch or finally block"},
+		{"try { f() } catch (1) {}", "expected next token to be IDENT, got INT instead"},
+		{"try { f() } finally {} catch (e) {}", "no prefix parse function for CATCH found"},
+		{"throw\nx", "throw statement at line 1 requires a value on the same line"},
+		{"throw;", "no prefix parse function for ; found"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestImportAndExportStatements(t *testing.T) {
+	input := `import "path/to/mod" as m;
+import "./helpers" as h
+export let answer = m.value + h.twice(21);
+export let [a, b] = m.pair`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 4 {
+		t.Fatalf("program.Statements does not contain 4 statements. got=%d",
+			len(program.Statements))
+	}
+
+	imports := []struct {
+		expectedPath  string
+		expectedAlias string
+	}{
+		{"path/to/mod", "m"},
+		{"./helpers", "h"},
+	}
+	for i, tt := range imports {
+		stmt, ok := program.Statements[i].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("program.Statements[%d] is not ast.ImportStatement. got=%T",
+				i, program.Statements[i])
+		}
+		if stmt.Path != tt.expectedPath {
+			t.Errorf("stmt.Path wrong. want=%q, got=%q", tt.expectedPath, stmt.Path)
+		}
+		testIdentifier(t, stmt.Alias, tt.expectedAlias)
+	}
+
+	export, ok := program.Statements[2].(*ast.ExportStatement)
+	if !ok {
+		t.Fatalf("program.Statements[2] is not ast.ExportStatement. got=%T",
+			program.Statements[2])
+	}
+	if !testLetStatement(t, export.Statement, "answer") {
+		return
+	}
+
+	infix, ok := export.Statement.Value.(*ast.InfixExpression)
+	if !ok {
+		t.Fatalf("export value is not ast.InfixExpression. got=%T", export.Statement.Value)
+	}
+	member, ok := infix.Left.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("infix.Left is not ast.MemberExpression. got=%T", infix.Left)
+	}
+	testIdentifier(t, member.Object, "m")
+	testIdentifier(t, member.Property, "value")
+
+	call, ok := infix.Right.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("infix.Right is not ast.CallExpression. got=%T", infix.Right)
+	}
+	if call.Function.String() != "h.twice" {
+		t.Errorf("call.Function wrong. got=%q", call.Function.String())
+	}
+
+	expected := `import "path/to/mod" as m;import "./helpers" as h;` +
+		`export let answer = (m.value + h.twice(21));export let [a, b] = m.pair;`
+	if program.String() != expected {
+		t.Errorf("program.String() wrong. want=%q, got=%q", expected, program.String())
+	}
+}
+
+func TestInvalidImportAndExportStatements(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"import mod as m;", "expected next token to be STRING, got IDENT instead"},
+		{`import "mod";`, "expected next token to be AS, got ; instead"},
+		{`import "" as m;`, "import at line 1 has an empty path"},
+		{`export fn() {};`, "expected next token to be LET, got FUNCTION instead"},
+		{"let f = fn() {\n  import \"mod\" as m\n}", "import statement at line 2 is only allowed at the top level"},
+		{"if (x) { export let y = 1 }", "export statement at line 1 is only allowed at the top level"},
+		{"m.1", "expected next token to be IDENT, got INT instead"},
+		{`import "mod`, "expected next token to be STRING, got ILLEGAL instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestMacroLiteralParsing(t *testing.T) {
+	input := `macro(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("statement is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	macro, ok := stmt.Expression.(*ast.MacroLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MacroLiteral. got=%T",
+			stmt.Expression)
+	}
+
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("macro literal parameters wrong. want 2, got=%d\n",
+			len(macro.Parameters))
+	}
+
+	testLiteralExpression(t, macro.Parameters[0], "x")
+	testLiteralExpression(t, macro.Parameters[1], "y")
+
+	if len(macro.Body.Statements) != 1 {
+		t.Fatalf("macro.Body.Statements has not 1 statements. got=%d\n",
+			len(macro.Body.Statements))
+	}
+
+	bodyStmt, ok := macro.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("macro body stmt is not ast.ExpressionStatement. got=%T",
+			macro.Body.Statements[0])
+	}
+
+	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
+}
+
+func TestInvalidMacroLiterals(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"macro(x = 1) { x }", "macro at line 1 cannot have default or rest parameters"},
+		{"macro(...xs) { x }", "macro at line 1 cannot have default or rest parameters"},
+		{"macro(1) { x }", `expected parameter to be IDENT, got INT ("1") instead`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestStructs(t *testing.T) {
+	input := `struct Point { x, y }
+fn (p Point) norm(scale) { p.x * p.x + p.y * p.y }
+let origin = Point { x: 0, y: 0 }
+let moved = Point {
+  x: origin.x + 1,
+  y: 2
+}.norm(1)`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 4 {
+		t.Fatalf("program.Statements does not contain 4 statements. got=%d",
+			len(program.Statements))
+	}
+
+	decl, ok := program.Statements[0].(*ast.StructStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.StructStatement. got=%T",
+			program.Statements[0])
+	}
+	testIdentifier(t, decl.Name, "Point")
+	if len(decl.Fields) != 2 {
+		t.Fatalf("decl.Fields does not contain 2 fields. got=%d", len(decl.Fields))
+	}
+	testIdentifier(t, decl.Fields[0], "x")
+	testIdentifier(t, decl.Fields[1], "y")
+
+	stmt, ok := program.Statements[1].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not ast.ExpressionStatement. got=%T",
+			program.Statements[1])
+	}
+	method, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
+	}
+	if method.Receiver == nil {
+		t.Fatalf("method.Receiver is nil")
+	}
+	testIdentifier(t, method.Receiver.Name, "p")
+	testIdentifier(t, method.Receiver.Type, "Point")
+	testIdentifier(t, method.Name, "norm")
+	if len(method.Parameters) != 1 {
+		t.Fatalf("method.Parameters does not contain 1 parameter. got=%d", len(method.Parameters))
+	}
+	testIdentifier(t, method.Parameters[0], "scale")
+
+	let := program.Statements[2].(*ast.LetStatement)
+	lit, ok := let.Value.(*ast.StructLiteral)
+	if !ok {
+		t.Fatalf("let.Value is not ast.StructLiteral. got=%T", let.Value)
+	}
+	testIdentifier(t, lit.Name, "Point")
+	if len(lit.Fields) != 2 {
+		t.Fatalf("lit.Fields does not contain 2 fields. got=%d", len(lit.Fields))
+	}
+	testIdentifier(t, lit.Fields[0].Name, "x")
+	testIntegerLiteral(t, lit.Fields[0].Value, 0)
+
+	expected := "struct Point { x, y }" +
+		"fn (p Point) norm(scale) ((p.x * p.x) + (p.y * p.y))" +
+		"let origin = Point { x: 0, y: 0 };" +
+		"let moved = Point { x: (origin.x + 1), y: 2 }.norm(1);"
+	if program.String() != expected {
+		t.Errorf("program.String() wrong. want=%q, got=%q", expected, program.String())
+	}
+}
+
+func TestInvalidStructs(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"struct Point { x, y, x }", "duplicate field x in struct Point at line 1"},
+		{"struct Point { x }; struct Point { y }", "struct Point at line 1 is already declared"},
+		{"struct Point { x: 1 }", "expected next token to be ,, got : instead"},
+		{"let f = fn() { struct Point { x } }", "struct statement at line 1 is only allowed at the top level"},
+		{"let p = Point { x: 1 }", "unknown struct Point at line 1"},
+		{"struct Point { x }\nlet p = Point { x: 1, x: 2 }", "duplicate field x in Point literal at line 2"},
+		{"struct Point { x }\nlet p = Point { x: 1, y: 2 }", "struct Point has no field y (line 2)"},
+		{"let p = f() { x: 1 }", "expected struct name before { at line 1, column 13"},
+		{"f(+) {}", "no prefix parse function for + found"},
+		{"f(,) { x: 1 }", "no prefix parse function for , found"},
+		{"try { throw x + catch (e) { e } finally { 0 } }", "no prefix parse function for CATCH found"},
+		{"struct P { x }\nP { x: + }", "no prefix parse function for + found"},
+		{"fn (p Point) norm() { p.x }", "expected next token to be ), got IDENT instead"},
+		{"fn (p Point) norm() { p.x }\nstruct Point { x }", "expected next token to be ), got IDENT instead"},
+		{"struct P { x }\nfn (p P) f() {}\nfn (q P) f() {}", "duplicate method P.f at line 3"},
+		{"struct P { x }\nfn (p P) f(p) {}", "duplicate parameter p"},
+		{"struct P { x }\nfn (p P, q P) f() {}", "expected next token to be ), got , instead"},
+		{"struct P { x }\nlet f = fn() { fn (p P) f() {} }", "method definition at line 2 is only allowed at the top level"},
+		{"struct P { x }\nmacro (p P) f() {}", "expected next token to be ), got IDENT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestStructLiteralsAfterSyntaxError(t *testing.T) {
+	input := "struct P { x }\nlet a = 1 +\nlet p = P { x: 1, }\nlet q = P { x: 2 }"
+	expected := []string{
+		"no prefix parse function for LET found",
+		"no prefix parse function for = found",
+	}
+
+	p := New(lexer.New(input))
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("wrong number of errors. want=%q, got=%q", expected, errors)
+	}
+	for i, e := range expected {
+		if errors[i] != e {
+			t.Errorf("wrong error %d. want=%q, got=%q", i, e, errors[i])
+		}
+	}
+}
+
+func TestDeclareStruct(t *testing.T) {
+	first := New(lexer.New("struct Point { x, y }"))
+	first.ParseProgram()
+	checkParserErrors(t, first)
+
+	second := New(lexer.New("Point { x: 1, y: 2 }"))
+	for _, stmt := range first.Structs() {
+		second.DeclareStruct(stmt)
+	}
+	second.ParseProgram()
+	checkParserErrors(t, second)
+}
+
+func TestTemplateLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		parts    int
+	}{
+		{"`hello`", "`hello`", 1},
+		{"``", "``", 0},
+		{"`${a}`", "`${a}`", 1},
+		{"`a ${b + c * 2} d`", "`a ${(b + (c * 2))} d`", 3},
+		{"`x = ${f(`${y}!`)}`", "`x = ${f(`${y}!`)}`", 2},
+		{"`line one\n  line two`", "`line one\n  line two`", 1},
+		{"`a \\` \\${b} ${c}`", "`a \\` \\${b} ${c}`", 2},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d",
+				len(program.Statements))
+		}
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("expression.String() wrong. want=%q, got=%q", tt.expected, stmt.Expression.String())
+		}
+
+		lit, ok := stmt.Expression.(*ast.TemplateLiteral)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.TemplateLiteral. got=%T", stmt.Expression)
+		}
+		if len(lit.Parts) != tt.parts {
+			t.Errorf("input %q: wrong number of parts. want=%d, got=%d", tt.input, tt.parts, len(lit.Parts))
+		}
+	}
+}
+
+func TestTemplateLiteralParts(t *testing.T) {
+	input := "let s = `Hi ${name}!`\nlet t = s"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d",
+			len(program.Statements))
+	}
+
+	lit, ok := program.Statements[0].(*ast.LetStatement).Value.(*ast.TemplateLiteral)
+	if !ok {
+		t.Fatalf("let value is not ast.TemplateLiteral. got=%T",
+			program.Statements[0].(*ast.LetStatement).Value)
+	}
+
+	if len(lit.Parts) != 3 {
+		t.Fatalf("lit.Parts does not contain 3 parts. got=%d", len(lit.Parts))
+	}
+	for i, expected := range []string{"Hi ", "", "!"} {
+		if i == 1 {
+			testIdentifier(t, lit.Parts[i], "name")
+			continue
+		}
+		text, ok := lit.Parts[i].(*ast.TemplateText)
+		if !ok {
+			t.Fatalf("lit.Parts[%d] is not ast.TemplateText. got=%T", i, lit.Parts[i])
+		}
+		if text.Value != expected {
+			t.Errorf("text.Value wrong. want=%q, got=%q", expected, text.Value)
+		}
+	}
+}
+
+func TestInvalidTemplateLiterals(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"`abc", "unterminated template literal starting at line 1"},
+		{"`a ${}`", "empty interpolation in template literal at line 1"},
+		{"`a ${b c}`", "expected next token to be }, got IDENT instead"},
+		{"`a ${b", "expected next token to be }, got EOF instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestIndexAndSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedStart interface{}
+		expectedEnd   interface{}
+		expectedStep  interface{}
+	}{
+		{"xs[1:3]", 1, 3, nil},
+		{"s[:n]", nil, "n", nil},
+		{"s[n:]", "n", nil, nil},
+		{"xs[:]", nil, nil, nil},
+		{"xs[1:10:2]", 1, 10, 2},
+		{"xs[::k]", nil, nil, "k"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		slice, ok := stmt.Expression.(*ast.SliceExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.SliceExpression. got=%T", stmt.Expression)
+		}
+
+		bounds := []struct {
+			name     string
+			actual   ast.Expression
+			expected interface{}
+		}{
+			{"Start", slice.Start, tt.expectedStart},
+			{"End", slice.End, tt.expectedEnd},
+			{"Step", slice.Step, tt.expectedStep},
+		}
+		for _, b := range bounds {
+			if b.expected == nil {
+				if b.actual != nil {
+					t.Errorf("input %q: slice.%s is not nil. got=%s", tt.input, b.name, b.actual.String())
+				}
+				continue
+			}
+			testLiteralExpression(t, b.actual, b.expected)
+		}
+	}
+
+	program := New(lexer.New("xs[i]")).ParseProgram()
+	index, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("expression is not ast.IndexExpression. got=%T",
+			program.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+	testIdentifier(t, index.Left, "xs")
+	testIdentifier(t, index.Index, "i")
+}
+
+func TestRangeExpressions(t *testing.T) {
+	tests := []struct {
+		input             string
+		expectedStart     interface{}
+		expectedEnd       interface{}
+		expectedInclusive bool
+	}{
+		{"0..10", 0, 10, false},
+		{"a..=b", "a", "b", true},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.RangeExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.RangeExpression. got=%T", stmt.Expression)
+		}
+		testLiteralExpression(t, exp.Start, tt.expectedStart)
+		testLiteralExpression(t, exp.End, tt.expectedEnd)
+		if exp.Inclusive != tt.expectedInclusive {
+			t.Errorf("exp.Inclusive wrong. want=%t, got=%t", tt.expectedInclusive, exp.Inclusive)
+		}
+	}
+}
+
+func TestInvalidRangeAndSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"a..b..c", "range expression at line 1 cannot be chained"},
+		{"xs[]", "missing index at line 1"},
+		{"xs[1:2:3:4]", "slice at line 1 has too many colons"},
+		{"xs[1 2]", "expected next token to be ], got INT instead"},
+		{"xs[1", "expected next token to be ], got EOF instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestConstStatements(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedIdentifier string
+		expectedValue      interface{}
+	}{
+		{"const x = 5;", "x", 5},
+		{"const limit = y", "limit", "y"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ConstStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ConstStatement. got=%T",
+				program.Statements[0])
+		}
+		testIdentifier(t, stmt.Name, tt.expectedIdentifier)
+		testLiteralExpression(t, stmt.Value, tt.expectedValue)
+	}
+
+	program := New(lexer.New("const x = 1 + 2")).ParseProgram()
+	if program.String() != "const x = (1 + 2);" {
+		t.Errorf("program.String() wrong. got=%q", program.String())
+	}
+
+	p := New(lexer.New("const [a] = xs"))
+	p.ParseProgram()
+	expected := "expected next token to be IDENT, got [ instead"
+	if len(p.Errors()) == 0 || p.Errors()[0] != expected {
+		t.Errorf("const with a pattern: wrong errors. want=%q first, got=%v", expected, p.Errors())
+	}
+}
+
+func TestBindingErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{
+			"const x = 1\nlet x = 2",
+			"x redeclared in this scope at line 2, column 5 (previously declared as constant at line 1, column 7)",
+		},
+		{
+			"const x = 1; const x = 2",
+			"x redeclared in this scope at line 1, column 20 (previously declared as constant at line 1, column 7)",
+		},
+		{
+			"let x = 1; let [a, x] = xs",
+			"x redeclared in this scope at line 1, column 20 (previous declaration at line 1, column 5)",
+		},
+		{
+			"let f = fn(x) { let x = 2 }",
+			"x redeclared in this scope at line 1, column 21 (previous declaration at line 1, column 12)",
+		},
+		{
+			"if (a) { const y = 1; let y = 2 }",
+			"y redeclared in this scope at line 1, column 27 (previously declared as constant at line 1, column 16)",
+		},
+		{
+			"try { a } catch (e) { let e = 1 }",
+			"e redeclared in this scope at line 1, column 27 (previous declaration at line 1, column 18)",
+		},
+		{"const [a] = xs", "expected next token to be IDENT, got [ instead"},
+		{
+			"let m = macro(a) { let a = 1 }",
+			"a redeclared in this scope at line 1, column 24 (previous declaration at line 1, column 15)",
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestShadowingWarnings(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedWarnings []string
+	}{
+		{"let x = 1; let f = fn(y) { let z = x + y }", []string{}},
+		{"let f = fn(x) { x }; let g = fn(x) { x }", []string{}},
+		{"let f = fn() { let x = 1 }; let x = 2", []string{}},
+		{"let x = fn() { 1 }; match (x) { x => x }", []string{}},
+		{
+			"let x = 1\nlet f = fn() {\n  let x = 2\n}",
+			[]string{"x at line 3, column 7 shadows the outer x declared at line 1, column 5"},
+		},
+		{
+			"let f = fn(n) { if (n) { const n = 1 } }",
+			[]string{"n at line 1, column 32 shadows the outer n declared at line 1, column 12"},
+		},
+		{
+			"import \"m\" as m\nlet f = fn() { let [m, _] = xs }",
+			[]string{"m at line 2, column 21 shadows the outer m declared at line 1, column 15"},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+		checkParserErrors(t, p)
+
+		warnings := p.Warnings()
+		if len(warnings) != len(tt.expectedWarnings) {
+			t.Errorf("input %q: wrong number of warnings. want=%v, got=%v",
+				tt.input, tt.expectedWarnings, warnings)
+			continue
+		}
+		for i, w := range tt.expectedWarnings {
+			if warnings[i] != w {
+				t.Errorf("input %q: wrong warning. want=%q, got=%q", tt.input, w, warnings[i])
+			}
+		}
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x: int = 5", "let x: int = 5;"},
+		{"let p: Point = q", "let p: Point = q;"},
+		{"fn(x: int, y: bool) {}", "fn(x: int, y: bool) "},
+		{"fn(x, y: int = 10, ...rest) -> bool { x }", "fn(x, y: int = 10, ...rest) -> bool x"},
+		{"let f: fn(int, fn(int) -> bool) -> int = g", "let f: fn(int, fn(int) -> bool) -> int = g;"},
+		{"let f: fn() = g", "let f: fn() = g;"},
+		{"struct P { x }; fn (p P) get() -> int { 1 }", "struct P { x }fn (p P) get() -> int 1"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("program.String() wrong. want=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("fn(x: int) -> fn(int) -> int { x }")).ParseProgram()
+	function := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+
+	param, ok := function.Types["x"].(*ast.NamedType)
+	if !ok || param.Name != "int" {
+		t.Errorf("function.Types[x] wrong. got=%v", function.Types["x"])
+	}
+	ret, ok := function.ReturnType.(*ast.FunctionType)
+	if !ok {
+		t.Fatalf("function.ReturnType is not ast.FunctionType. got=%T", function.ReturnType)
+	}
+	if len(ret.Parameters) != 1 || ret.Return.String() != "int" {
+		t.Errorf("function.ReturnType wrong. got=%s", ret.String())
+	}
+}
+
+func TestInvalidTypeAnnotations(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"let x: = 5", `expected type at line 1, got = ("=") instead`},
+		{"let x: 5 = 5", `expected type at line 1, got INT ("5") instead`},
+		{"fn(x: ) {}", `expected type at line 1, got ) (")") instead`},
+		{"fn(x) -> {}", `expected type at line 1, got { ("{") instead`},
+		{"let f: fn(int = g", "expected next token to be ,, got = instead"},
+		{"let [a]: int = xs", "expected next token to be =, got : instead"},
+		{"macro(x: int) { x }", "macro at line 1 cannot have type annotations"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestSpawnAndSelectExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"spawn f(x)", "spawn f(x)"},
+		{"spawn m.worker(c, 1 + 2)", "spawn m.worker(c, (1 + 2))"},
+		{"let t = spawn fn() { 1 }()", "let t = spawn fn() 1();"},
+		{"select { recv(c) => 1 }", "select { recv(c) => 1 }"},
+		{"select { recv(c) as v => v + 1, send(d, 2) => 0, _ => -1 }",
+			"select { recv(c) as v => (v + 1), send(d, 2) => 0, _ => (-1) }"},
+		{"select {\n  recv(c) as v => v\n  , _ => 0\n}", "select { recv(c) as v => v, _ => 0 }"},
+		{"select { recv(c) as v => v, recv(d) as v => v }", "select { recv(c) as v => v, recv(d) as v => v }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("program.String() wrong. want=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("select { recv(c) as v => v, _ => 0 }")).ParseProgram()
+	exp := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.SelectExpression)
+	if len(exp.Cases) != 2 {
+		t.Fatalf("exp.Cases does not contain 2 cases. got=%d", len(exp.Cases))
+	}
+	if exp.Cases[0].Value == nil || exp.Cases[0].Value.Value != "v" {
+		t.Errorf("exp.Cases[0].Value wrong. got=%v", exp.Cases[0].Value)
+	}
+	if exp.Cases[1].Comm != nil {
+		t.Errorf("exp.Cases[1].Comm is not nil. got=%s", exp.Cases[1].Comm.String())
+	}
+}
+
+func TestInvalidSpawnAndSelectExpressions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"spawn f", "spawn at line 1, column 1 requires a function call"},
+		{"spawn 1 + 2", "spawn at line 1, column 1 requires a function call"},
+		{"spawn -f(+)", "no prefix parse function for + found"},
+		{"select {}", "select expression at line 1 has no cases"},
+		{"select { f(c) => 1 }", "select case at line 1, column 10 must be recv(c) or send(c, v)"},
+		{"select { recv(c, d) => 1 }", "select case at line 1, column 10 must be recv(c) or send(c, v)"},
+		{"select { f(+) => 1 }", "no prefix parse function for + found"},
+		{"select { send(c, 1) as v => v }", "send case at line 1 cannot bind a value"},
+		{"select { _ => 1, _ => 2 }", "select expression at line 1 has more than one default case"},
+		{"select { recv(c) as 1 => 1 }", "expected next token to be IDENT, got INT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected parser errors, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
@@ -2,11 +2,18 @@ package parser
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 )
 
 var traceLevel int = 0
 
+/*
+	トレースの出力先. トレースが要らない場合は ioutil.Discard にする.
+ */
+var TraceOutput io.Writer = os.Stdout
+
 const traceIdentPlaceholder string = "\t"
 
 func identLevel() string {
@@ -14,7 +21,7 @@ func identLevel() string {
 }
 
 func tracePrint(fs string) {
-	fmt.Printf("%s%s\n", identLevel(), fs)
+	fmt.Fprintf(TraceOutput, "%s%s\n", identLevel(), fs)
 }
 
 func incIdent() { traceLevel = traceLevel + 1 }
@@ -3,7 +3,9 @@ package repl
 import (
 	"bufio"
 	"fmt"
+	"github.com/WTBacon/goInterpreter/ast"
 	"github.com/WTBacon/goInterpreter/lexer"
+	"github.com/WTBacon/goInterpreter/macro"
 	"github.com/WTBacon/goInterpreter/parser"
 	"io"
 )
@@ -16,6 +18,8 @@ const PROMPT = ">> "
  */
 func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
+	macroEnv := macro.NewEnv()
+	structs := map[string]*ast.StructStatement{}
 
 	for {
 		fmt.Printf(PROMPT)
@@ -27,14 +31,30 @@ func Start(in io.Reader, out io.Writer) {
 		line := scanner.Text()
 		l := lexer.New(line)
 		p := parser.New(l)
+		for _, stmt := range structs {
+			p.DeclareStruct(stmt)
+		}
 
 		program := p.ParseProgram()
 		if len(p.Errors()) != 0 {
 			printParserErrors(out, p.Errors())
 			continue
 		}
+		if len(p.Warnings()) != 0 {
+			printParserWarnings(out, p.Warnings())
+		}
+		for name, stmt := range p.Structs() {
+			structs[name] = stmt
+		}
+
+		macro.DefineMacros(program, macroEnv)
+		expanded, err := macro.ExpandMacros(program, macroEnv)
+		if err != nil {
+			io.WriteString(out, " macro expansion error: "+err.Error()+"\n")
+			continue
+		}
 
-		io.WriteString(out, program.String())
+		io.WriteString(out, expanded.String())
 		io.WriteString(out, "\n")
 	}
 }
@@ -46,3 +66,10 @@ func printParserErrors(out io.Writer, errors []string) {
 		io.WriteString(out, "\t"+msg+"\n")
 	}
 }
+
+func printParserWarnings(out io.Writer, warnings []string) {
+	io.WriteString(out, " parser warnings:\n")
+	for _, msg := range warnings {
+		io.WriteString(out, "\t"+msg+"\n")
+	}
+}
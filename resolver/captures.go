@@ -0,0 +1,50 @@
+package resolver
+
+import (
+	"fmt"
+	"github.com/WTBacon/goInterpreter/ast"
+)
+
+/*
+	関数リテラルが捕捉する自由変数 1 つを表す構造体型.
+	クロージャを作るときに, すぐ外側の関数のどこから値（またはセル）を取ってくるかを表す.
+	Decl	: 捕捉する変数を束縛している識別子
+	Local	: true なら外側の関数のローカル変数を, false なら外側の関数が捕捉している自由変数を捕捉する
+	Index	: Local なら外側の関数でのスロット番号, そうでなければ外側の関数の Captures での番号
+ */
+type Capture struct {
+	Decl  *ast.Identifier
+	Local bool
+	Index int
+}
+
+func (c *Capture) String() string {
+	if c.Local {
+		return fmt.Sprintf("%s: local %d", c.Decl.Value, c.Index)
+	}
+	return fmt.Sprintf("%s: free %d", c.Decl.Value, c.Index)
+}
+
+/*
+	関数 f とその外側の関数に, sym を自由変数として追加して, f の captures での番号を返すメソッド.
+	sym を束縛している関数の内側にある関数は, 全て sym を捕捉する.（ex. fn(a) { fn() { fn() { a } } } の
+	2 番目の関数は, 自分では a を参照しないが, 3 番目の関数に渡すために a を捕捉する）
+ */
+func (r *resolver) capture(sym *symbol, f *function) int {
+	for i, c := range f.captures {
+		if c.Decl == sym.ident {
+			return i
+		}
+	}
+
+	c := &Capture{Decl: sym.ident}
+	if f.outer == sym.function {
+		c.Local = true
+		c.Index = sym.index
+		r.info.Captured[sym.ident] = true
+	} else {
+		c.Index = r.capture(sym, f.outer)
+	}
+	f.captures = append(f.captures, c)
+	return len(f.captures) - 1
+}
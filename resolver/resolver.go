@@ -0,0 +1,698 @@
+package resolver
+
+import (
+	"fmt"
+	"github.com/WTBacon/goInterpreter/ast"
+	"sort"
+)
+
+/*
+	組み込み関数の名前. どのスコープでも定義されていない名前は, ここから探す.
+	まだ評価器がないので, 実装する予定の組み込み関数を並べた仮の一覧.
+	chan, send, recv, close は spawn 式と select 式で使うチャネル操作の予定.
+ */
+var Builtins = []string{"len", "first", "last", "rest", "push", "puts", "chan", "send", "recv", "close"}
+
+/*
+	識別子が参照する束縛の種類を表す型.
+ */
+type Kind int
+
+const (
+	Local   Kind = iota // 参照している関数自身の仮引数やローカル変数
+	Free                // 外側の関数のローカル変数（クロージャが捕捉する自由変数）
+	Global              // 関数の外で束縛された変数
+	Builtin             // 組み込み関数
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Local:
+		return "local"
+	case Free:
+		return "free"
+	case Global:
+		return "global"
+	case Builtin:
+		return "builtin"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+/*
+	識別子を解決した結果を表す構造体型.
+	Kind	: 束縛の種類
+	Depth	: 参照している関数から, 束縛している関数まで遡る関数の数. Free の場合だけ 1 以上になる
+	Index	: スロット番号. Local と Free は束縛している関数の中での番号, Global はプログラム全体での番号,
+			  Builtin は Builtins の中での番号
+	Decl	: 名前を束縛している識別子. Builtin の場合は nil
+ */
+type Binding struct {
+	Kind  Kind
+	Depth int
+	Index int
+	Decl  *ast.Identifier
+}
+
+func (b *Binding) String() string {
+	if b.Kind == Free {
+		return fmt.Sprintf("%s %d (depth %d)", b.Kind, b.Index, b.Depth)
+	}
+	return fmt.Sprintf("%s %d", b.Kind, b.Index)
+}
+
+/*
+	名前解決で見つかったエラーや警告を表す構造体型.
+	Line, Column	: 原因になった識別子の位置
+	Message			: 内容
+ */
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+/*
+	名前解決の結果を表す構造体型.
+	Defs		: 名前を束縛している識別子から, その束縛へのマップ
+	Uses		: 名前を参照している識別子から, 参照先の束縛へのマップ
+	Captures	: 関数リテラルから, その関数が捕捉する自由変数へのマップ. 自由変数がなければ空のスライス
+	Captured	: 関数の中で束縛された変数のうち, 内側の関数に捕捉されるものの束縛している識別子の集合
+	TailCalls	: 関数の末尾位置にある呼び出し（CallExpression と PipeExpression）の集合
+ */
+type Info struct {
+	Defs      map[*ast.Identifier]*Binding
+	Uses      map[*ast.Identifier]*Binding
+	Captures  map[*ast.FunctionLiteral][]*Capture
+	Captured  map[*ast.Identifier]bool
+	TailCalls map[ast.Expression]bool
+}
+
+/*
+	識別子の束縛を返すメソッド. 束縛も参照もしていない識別子（メンバー名など）には nil を返す.
+ */
+func (info *Info) BindingOf(ident *ast.Identifier) *Binding {
+	if b, ok := info.Uses[ident]; ok {
+		return b
+	}
+	return info.Defs[ident]
+}
+
+/*
+	プログラムの名前を解決して, 各識別子が参照する束縛を求める関数.
+	以下をエラーとして報告する.
+	- どこでも束縛されていない名前の参照
+	以下を警告として報告する.（トップレベルの変数とレシーバは対象外）
+	- 一度も参照されない変数
+	- 一度も参照されない仮引数
+	同じ名前の束縛や外側の名前を覆い隠す束縛は, パーサーが CheckBindings で報告するので, ここでは報告しない.
+	関数の中からは, 後で束縛されるトップレベルの変数も参照できる.
+	マクロリテラルの本体は展開するときに評価されるので, マクロを展開してから解決すること.
+ */
+func Resolve(program *ast.Program) (info *Info, errors []*Error, warnings []*Error) {
+	r := newResolver(false)
+	r.run(program)
+	return r.info, r.errors, r.warnings
+}
+
+/*
+	プログラムのスコープを作って, 名前の束縛だけを検査する関数. パーサーがプログラムの最後に呼び出す.
+	以下をエラーとして報告する.
+	- 同じスコープで同じ名前を 2 度束縛している（前の束縛が const 文なら, そのことも示す）
+	以下を警告として報告する.
+	- let 文や const 文が, 外側のスコープの名前を覆い隠している
+	マクロを展開する前のプログラムを検査するので, マクロリテラルの仮引数と本体も検査する.
+	メッセージは位置を含むので, Error() でなく Message をそのまま使う.
+ */
+func CheckBindings(program *ast.Program) (errors []*Error, warnings []*Error) {
+	r := newResolver(true)
+	r.run(program)
+	return r.errors, r.warnings
+}
+
+func newResolver(bindings bool) *resolver {
+	return &resolver{
+		info: &Info{
+			Defs:      map[*ast.Identifier]*Binding{},
+			Uses:      map[*ast.Identifier]*Binding{},
+			Captures:  map[*ast.FunctionLiteral][]*Capture{},
+			Captured:  map[*ast.Identifier]bool{},
+			TailCalls: map[ast.Expression]bool{},
+		},
+		globals:  map[string]*symbol{},
+		bindings: bindings,
+	}
+}
+
+func (r *resolver) run(program *ast.Program) {
+	r.declareGlobals(program)
+	r.push()
+	for _, s := range program.Statements {
+		r.statement(s)
+	}
+	r.pop()
+
+	sortErrors(r.warnings)
+}
+
+/*
+	束縛の種類（警告の出し分けに使う）を表す型.
+ */
+type symbolKind int
+
+const (
+	letSymbol       symbolKind = iota // let 文
+	constSymbol                       // const 文
+	patternSymbol                     // match 式のパターン, catch の仮引数, select 式で受け取った値
+	parameterSymbol                   // 関数の仮引数
+	receiverSymbol                    // メソッドのレシーバ
+	importSymbol                      // import 文のエイリアス
+)
+
+/*
+	束縛された名前を表す構造体型.
+	ident		: 名前を束縛している識別子
+	kind		: 束縛の種類
+	function	: 束縛している関数. 関数の外なら nil
+	index		: スロット番号
+	used		: 参照されたか
+ */
+type symbol struct {
+	ident    *ast.Identifier
+	kind     symbolKind
+	function *function
+	index    int
+	used     bool
+}
+
+/*
+	関数リテラル 1 つ分のスロットを表す構造体型.
+	outer		: 外側の関数. トップレベルの関数では nil
+	slots		: 割り当てたスロットの数
+	captures	: この関数が捕捉する自由変数
+	tryDepth	: 解決している位置を囲んでいる, この関数の中の try 式の数
+ */
+type function struct {
+	outer    *function
+	slots    int
+	captures []*Capture
+	tryDepth int
+}
+
+/*
+	レキシカルスコープを表す構造体型.
+	outer	: 外側のスコープ
+	names	: このスコープで束縛された名前から, その束縛へのマップ
+ */
+type scope struct {
+	outer *scope
+	names map[string]*symbol
+}
+
+/*
+	名前解決の状態を表す構造体型.
+	info		: 解決結果
+	errors		: 見つかったエラー
+	warnings	: 見つかった警告
+	scope		: 現在のスコープ
+	function	: 現在の関数. 関数の外なら nil
+	globals		: トップレベルの文が束縛する名前から, その束縛へのマップ
+	nextGlobal	: 次に割り当てるグローバル変数のスロット番号
+	bindings	: CheckBindings から呼ばれたか. true なら束縛の検査結果だけを, false なら名前解決の結果だけを報告する
+ */
+type resolver struct {
+	info       *Info
+	errors     []*Error
+	warnings   []*Error
+	scope      *scope
+	function   *function
+	globals    map[string]*symbol
+	nextGlobal int
+	bindings   bool
+}
+
+/*
+	トップレベルの文が束縛する名前に, 先にスロットを割り当てておくメソッド.
+	関数の本体から, 後で束縛されるトップレベルの変数を参照できるようにするため.
+ */
+func (r *resolver) declareGlobals(program *ast.Program) {
+	for _, s := range program.Statements {
+		if export, ok := s.(*ast.ExportStatement); ok && export.Statement != nil {
+			s = export.Statement
+		}
+		for _, ident := range declaredNames(s) {
+			if _, ok := r.globals[ident.Value]; !ok && ident.Value != "_" {
+				r.globals[ident.Value] = r.newGlobal(ident, letSymbol)
+			}
+		}
+	}
+}
+
+/*
+	文が現在のスコープに束縛する識別子を返す関数.
+ */
+func declaredNames(s ast.Statement) []*ast.Identifier {
+	switch s := s.(type) {
+	case *ast.LetStatement:
+		if s.Pattern != nil {
+			return ast.PatternIdentifiers(s.Pattern)
+		}
+		return []*ast.Identifier{s.Name}
+	case *ast.ConstStatement:
+		return []*ast.Identifier{s.Name}
+	case *ast.ImportStatement:
+		if s.Alias != nil {
+			return []*ast.Identifier{s.Alias}
+		}
+	}
+	return nil
+}
+
+func (r *resolver) newGlobal(ident *ast.Identifier, kind symbolKind) *symbol {
+	sym := &symbol{ident: ident, kind: kind, index: r.nextGlobal}
+	r.nextGlobal += 1
+	return sym
+}
+
+func (r *resolver) push() {
+	r.scope = &scope{outer: r.scope, names: map[string]*symbol{}}
+}
+
+/*
+	スコープを閉じて, 参照されなかった変数と仮引数を警告するメソッド.
+ */
+func (r *resolver) pop() {
+	for _, sym := range r.scope.names {
+		if sym.used || sym.function == nil || r.bindings {
+			continue
+		}
+		switch sym.kind {
+		case letSymbol, constSymbol, patternSymbol:
+			r.warnf(sym.ident, "%s declared but not used", sym.ident.Value)
+		case parameterSymbol:
+			r.warnf(sym.ident, "parameter %s is not used", sym.ident.Value)
+		}
+	}
+	r.scope = r.scope.outer
+}
+
+/*
+	現在のスコープに ident の名前を束縛するメソッド.
+	束縛を検査している場合は, 同じスコープで束縛済みの名前ならエラーを,
+	let 文や const 文が外側のスコープの名前を覆い隠すなら警告を報告する.
+ */
+func (r *resolver) declare(ident *ast.Identifier, kind symbolKind) {
+	if ident == nil || ident.Value == "_" {
+		return
+	}
+
+	if r.bindings {
+		r.checkBinding(ident, kind)
+	}
+
+	var sym *symbol
+	if r.function != nil {
+		sym = &symbol{ident: ident, kind: kind, function: r.function, index: r.function.slots}
+		r.function.slots += 1
+	} else if g, ok := r.globals[ident.Value]; ok && g.ident == ident {
+		// トップレベルの文で束縛する名前には, 割り当て済みのスロットを使う.
+		sym = g
+		sym.kind = kind
+	} else {
+		sym = r.newGlobal(ident, kind)
+	}
+	r.scope.names[ident.Value] = sym
+
+	r.info.Defs[ident] = &Binding{Kind: sym.bindingKind(), Index: sym.index, Decl: ident}
+}
+
+func (r *resolver) checkBinding(ident *ast.Identifier, kind symbolKind) {
+	if prev, ok := r.scope.names[ident.Value]; ok {
+		if prev.kind == constSymbol {
+			r.errorf(ident, "%s redeclared in this scope at %s (previously declared as constant at %s)",
+				ident.Value, position(ident), position(prev.ident))
+		} else {
+			r.errorf(ident, "%s redeclared in this scope at %s (previous declaration at %s)",
+				ident.Value, position(ident), position(prev.ident))
+		}
+		return
+	}
+
+	if kind == letSymbol || kind == constSymbol {
+		for s := r.scope.outer; s != nil; s = s.outer {
+			if outer, ok := s.names[ident.Value]; ok {
+				r.warnf(ident, "%s at %s shadows the outer %s declared at %s",
+					ident.Value, position(ident), ident.Value, position(outer.ident))
+				return
+			}
+		}
+	}
+}
+
+/*
+	識別子のソースコード上の位置を "line <行番号>, column <列番号>" の形式で返すヘルパー関数.
+ */
+func position(ident *ast.Identifier) string {
+	return fmt.Sprintf("line %d, column %d", ident.Token.Line, ident.Token.Column)
+}
+
+func (sym *symbol) bindingKind() Kind {
+	if sym.function == nil {
+		return Global
+	}
+	return Local
+}
+
+/*
+	識別子が参照している名前を解決するメソッド.
+ */
+func (r *resolver) resolve(ident *ast.Identifier) {
+	sym, ok := r.lookup(ident.Value)
+	if !ok {
+		for i, name := range Builtins {
+			if name == ident.Value {
+				r.info.Uses[ident] = &Binding{Kind: Builtin, Index: i}
+				return
+			}
+		}
+		if !r.bindings {
+			r.errorf(ident, "undefined: %s", ident.Value)
+		}
+		return
+	}
+
+	sym.used = true
+	binding := &Binding{Kind: sym.bindingKind(), Index: sym.index, Decl: sym.ident}
+	if sym.function != nil {
+		for f := r.function; f != sym.function; f = f.outer {
+			binding.Depth += 1
+		}
+		if binding.Depth > 0 {
+			binding.Kind = Free
+			r.capture(sym, r.function)
+		}
+	}
+	r.info.Uses[ident] = binding
+}
+
+/*
+	外側のスコープを辿って, name の束縛を探すメソッド.
+	関数の中で見つからなければ, トップレベルの文が束縛する名前から探す.
+ */
+func (r *resolver) lookup(name string) (*symbol, bool) {
+	for s := r.scope; s != nil; s = s.outer {
+		if sym, ok := s.names[name]; ok {
+			return sym, true
+		}
+	}
+	if r.function != nil {
+		sym, ok := r.globals[name]
+		return sym, ok
+	}
+	return nil, false
+}
+
+func (r *resolver) statement(s ast.Statement) {
+	switch s := s.(type) {
+	case *ast.ExpressionStatement:
+		r.expression(s.Expression)
+
+	case *ast.LetStatement:
+		r.letStatement(s)
+
+	case *ast.ConstStatement:
+		r.expression(s.Value)
+		r.declare(s.Name, constSymbol)
+
+	case *ast.ReturnStatement:
+		r.expression(s.ReturnValue)
+		if r.function != nil && r.function.tryDepth == 0 {
+			r.tailExpression(s.ReturnValue)
+		}
+
+	case *ast.ThrowStatement:
+		r.expression(s.Value)
+
+	case *ast.ImportStatement:
+		r.declare(s.Alias, importSymbol)
+
+	case *ast.ExportStatement:
+		if s.Statement != nil {
+			r.letStatement(s.Statement)
+		}
+
+	case *ast.BlockStatement:
+		r.block(s)
+	}
+}
+
+/*
+	let 文の名前を解決するメソッド.
+	値が関数リテラルなら, 自分自身を再帰的に呼び出せるように先に名前を束縛する.
+ */
+func (r *resolver) letStatement(s *ast.LetStatement) {
+	if s.Pattern != nil {
+		r.expression(s.Value)
+		for _, ident := range ast.PatternIdentifiers(s.Pattern) {
+			r.declare(ident, letSymbol)
+		}
+		return
+	}
+
+	if _, ok := s.Value.(*ast.FunctionLiteral); ok {
+		r.declare(s.Name, letSymbol)
+		r.expression(s.Value)
+		return
+	}
+	r.expression(s.Value)
+	r.declare(s.Name, letSymbol)
+}
+
+/*
+	ブロックの文を, 新しいスコープで解決するメソッド.
+ */
+func (r *resolver) block(b *ast.BlockStatement) {
+	if b == nil {
+		return
+	}
+	r.push()
+	r.statements(b)
+	r.pop()
+}
+
+/*
+	ブロックの文を, 現在のスコープで解決するヘルパーメソッド.
+ */
+func (r *resolver) statements(b *ast.BlockStatement) {
+	if b == nil {
+		return
+	}
+	for _, s := range b.Statements {
+		r.statement(s)
+	}
+}
+
+func (r *resolver) expression(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case nil:
+		return
+
+	case *ast.Identifier:
+		r.resolve(exp)
+
+	case *ast.PrefixExpression:
+		r.expression(exp.Right)
+
+	case *ast.InfixExpression:
+		r.expression(exp.Left)
+		r.expression(exp.Right)
+
+	case *ast.IfExpression:
+		r.expression(exp.Condition)
+		r.block(exp.Consequence)
+		r.block(exp.Alternative)
+
+	case *ast.FunctionLiteral:
+		r.functionLiteral(exp)
+
+	case *ast.MacroLiteral:
+		if r.bindings {
+			r.macroLiteral(exp)
+		}
+
+	case *ast.CallExpression:
+		r.expression(exp.Function)
+		for _, a := range exp.Arguments {
+			r.expression(a)
+		}
+		// キーワード引数の名前は仮引数の名前なので, 値だけを解決する.
+		for _, a := range exp.NamedArguments {
+			r.expression(a.Value)
+		}
+
+	case *ast.SpreadExpression:
+		r.expression(exp.Value)
+
+	case *ast.PipeExpression:
+		r.expression(exp.Left)
+		r.expression(exp.Right)
+
+	case *ast.MatchExpression:
+		r.expression(exp.Subject)
+		for _, arm := range exp.Arms {
+			r.push()
+			r.pattern(arm.Pattern)
+			r.expression(arm.Guard)
+			r.expression(arm.Body)
+			r.pop()
+		}
+
+	case *ast.SpawnExpression:
+		r.expression(exp.Call)
+
+	case *ast.SelectExpression:
+		for _, sc := range exp.Cases {
+			if sc.Comm != nil {
+				r.expression(sc.Comm)
+			}
+			r.push()
+			r.declare(sc.Value, patternSymbol)
+			r.expression(sc.Body)
+			r.pop()
+		}
+
+	case *ast.TryExpression:
+		// try 式の中の return 文は, 末尾位置にならない.
+		if r.function != nil {
+			r.function.tryDepth += 1
+			defer func() { r.function.tryDepth -= 1 }()
+		}
+		r.block(exp.Block)
+		if exp.Catch != nil {
+			r.push()
+			r.declare(exp.CatchParam, patternSymbol)
+			r.statements(exp.Catch)
+			r.pop()
+		}
+		r.block(exp.Finally)
+
+	case *ast.MemberExpression:
+		// メンバー名は, オブジェクトのメンバーなので解決しない.
+		r.expression(exp.Object)
+
+	case *ast.StructLiteral:
+		// 構造体型の名前とフィールド名は, 変数ではないので解決しない.
+		for _, f := range exp.Fields {
+			r.expression(f.Value)
+		}
+
+	case *ast.TemplateLiteral:
+		for _, part := range exp.Parts {
+			r.expression(part)
+		}
+
+	case *ast.RangeExpression:
+		r.expression(exp.Start)
+		r.expression(exp.End)
+
+	case *ast.IndexExpression:
+		r.expression(exp.Left)
+		r.expression(exp.Index)
+
+	case *ast.SliceExpression:
+		r.expression(exp.Left)
+		r.expression(exp.Start)
+		r.expression(exp.End)
+		r.expression(exp.Step)
+	}
+}
+
+/*
+	関数リテラルの名前を解決するメソッド.
+	仮引数と関数本体の文は, 同じスコープに属する.
+ */
+func (r *resolver) functionLiteral(fn *ast.FunctionLiteral) {
+	r.function = &function{outer: r.function, captures: []*Capture{}}
+	r.push()
+
+	if fn.Receiver != nil {
+		r.declare(fn.Receiver.Name, receiverSymbol)
+	}
+	for _, param := range fn.Parameters {
+		r.expression(fn.Defaults[param.Value])
+		r.declare(param, parameterSymbol)
+	}
+	if fn.Rest != nil {
+		r.declare(fn.Rest, parameterSymbol)
+	}
+	r.statements(fn.Body)
+	r.tailBlock(fn.Body)
+
+	r.pop()
+	r.info.Captures[fn] = r.function.captures
+	r.function = r.function.outer
+}
+
+/*
+	マクロリテラルの仮引数と本体の束縛を検査するメソッド.
+	仮引数と本体の文は, 関数リテラルと同じく同じスコープに属する.
+ */
+func (r *resolver) macroLiteral(m *ast.MacroLiteral) {
+	r.function = &function{outer: r.function, captures: []*Capture{}}
+	r.push()
+	for _, param := range m.Parameters {
+		r.declare(param, parameterSymbol)
+	}
+	r.statements(m.Body)
+	r.pop()
+	r.function = r.function.outer
+}
+
+/*
+	match 式のパターンが束縛する名前を, 現在のスコープに束縛するメソッド.
+ */
+func (r *resolver) pattern(pattern ast.Pattern) {
+	ast.Walk(pattern, func(node ast.Node) bool {
+		if lit, ok := node.(*ast.LiteralPattern); ok {
+			r.expression(lit.Value)
+			return false
+		}
+		return true
+	})
+	for _, ident := range ast.PatternIdentifiers(pattern) {
+		r.declare(ident, patternSymbol)
+	}
+}
+
+func (r *resolver) errorf(ident *ast.Identifier, format string, args ...interface{}) {
+	r.errors = append(r.errors, newError(ident, format, args...))
+}
+
+func (r *resolver) warnf(ident *ast.Identifier, format string, args ...interface{}) {
+	r.warnings = append(r.warnings, newError(ident, format, args...))
+}
+
+func newError(ident *ast.Identifier, format string, args ...interface{}) *Error {
+	return &Error{
+		Line:    ident.Token.Line,
+		Column:  ident.Token.Column,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+/*
+	エラーをソースコード上に現れる順に並べる関数.
+	警告はスコープを閉じるときに見つかるので, 内側のスコープのものから順に追加されるため.
+ */
+func sortErrors(errors []*Error) {
+	sort.SliceStable(errors, func(i, j int) bool {
+		if errors[i].Line != errors[j].Line {
+			return errors[i].Line < errors[j].Line
+		}
+		return errors[i].Column < errors[j].Column
+	})
+}
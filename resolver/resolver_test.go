@@ -0,0 +1,291 @@
+package resolver_test
+
+import (
+	"github.com/WTBacon/goInterpreter/ast"
+	"github.com/WTBacon/goInterpreter/lexer"
+	"github.com/WTBacon/goInterpreter/parser"
+	"github.com/WTBacon/goInterpreter/resolver"
+	"testing"
+)
+
+func TestResolveBindings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string // 参照している識別子の解決結果（出現順）
+	}{
+		{"let x = 1; x", []string{"x: global 0"}},
+		{"let x = 1; let y = 2; y + x", []string{"y: global 1", "x: global 0"}},
+		{"len(1)", []string{"len: builtin 0"}},
+		{"puts(1)", []string{"puts: builtin 5"}},
+		{"fn(a, b) { b + a }", []string{"b: local 1", "a: local 0"}},
+		{"fn(a) { let b = a; b }", []string{"a: local 0", "b: local 1"}},
+		{"fn(a) { fn(b) { a + b } }", []string{"a: free 0 (depth 1)", "b: local 0"}},
+		{"fn(a) { fn() { fn() { a } } }", []string{"a: free 0 (depth 2)"}},
+		{"let f = fn() { g() }; let g = fn() { 1 }", []string{"g: global 1"}},
+		{"let f = fn(n) { f(n) }", []string{"f: global 0", "n: local 0"}},
+		{"fn() { let f = fn(n) { f(n) }; f }", []string{"f: free 0 (depth 1)", "n: local 0", "f: local 0"}},
+		{"let x = 1; fn(x) { x }", []string{"x: local 0"}},
+		{"fn(a, b = a) { b }", []string{"a: local 0", "b: local 1"}},
+		{"fn(...xs) { xs }", []string{"xs: local 0"}},
+		{"struct P { x }; fn (p P) get() { p.x }", []string{"p: local 0"}},
+		{"fn(x) { match (x) { [a, b] => a + b } }", []string{"x: local 0", "a: local 1", "b: local 2"}},
+		{"try { 1 } catch (e) { e }", []string{"e: global 0"}},
+		{"let f = fn(a) { a }; f(a: 1).a", []string{"a: local 0", "f: global 0"}},
+		{"import \"m\" as m; m.value", []string{"m: global 0"}},
+		{"fn(c) { spawn puts(c); select { recv(c) as v => v, _ => close(c) } }",
+			[]string{"puts: builtin 5", "c: local 0", "recv: builtin 8", "c: local 0", "v: local 1", "close: builtin 9", "c: local 0"}},
+		{"fn(pair) { let [a, b] = pair; `${b}${a}` }", []string{"pair: local 0", "b: local 2", "a: local 1"}},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		info, errors, _ := resolver.Resolve(program)
+		if len(errors) != 0 {
+			t.Errorf("input %q: unexpected errors: %v", tt.input, errors)
+			continue
+		}
+
+		uses := []string{}
+		ast.Walk(program, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Identifier); ok {
+				if b, ok := info.Uses[ident]; ok {
+					uses = append(uses, ident.Value+": "+b.String())
+				}
+			}
+			return true
+		})
+
+		if len(uses) != len(tt.expected) {
+			t.Errorf("input %q: wrong uses. want=%v, got=%v", tt.input, tt.expected, uses)
+			continue
+		}
+		for i, use := range uses {
+			if use != tt.expected[i] {
+				t.Errorf("input %q: wrong use %d. want=%q, got=%q", tt.input, i, tt.expected[i], use)
+			}
+		}
+	}
+}
+
+func TestResolveDeclarations(t *testing.T) {
+	program := parse(t, "let x = 1; fn(a) { let b = a; b }")
+	info, _, _ := resolver.Resolve(program)
+
+	x := program.Statements[0].(*ast.LetStatement).Name
+	if b := info.BindingOf(x); b == nil || b.Kind != resolver.Global || b.Decl != x {
+		t.Errorf("binding of x wrong. got=%v", b)
+	}
+
+	fn := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	b := fn.Body.Statements[0].(*ast.LetStatement).Name
+	if binding := info.BindingOf(b); binding == nil || binding.String() != "local 1" {
+		t.Errorf("binding of b wrong. got=%v", binding)
+	}
+
+	use := fn.Body.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.Identifier)
+	if binding := info.BindingOf(use); binding == nil || binding.Decl != b {
+		t.Errorf("binding of b use wrong. got=%v", binding)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedErrors []string
+	}{
+		{"x", []string{"1:1: undefined: x"}},
+		{"x; let x = 1", []string{"1:1: undefined: x"}},
+		{"let x = x + 1", []string{"1:9: undefined: x"}},
+		{"fn() { y }", []string{"1:8: undefined: y"}},
+		{"if (true) { let y = 1 }; y", []string{"1:26: undefined: y"}},
+		{"fn(a = b, b = 1) { a + b }", []string{"1:8: undefined: b"}},
+		{"fn() { let g = fn() { h() }; let h = fn() { 1 }; g }", []string{"1:23: undefined: h"}},
+		{"match (1) { n if m => n }", []string{"1:18: undefined: m"}},
+		{"let f = fn(a) { a }; f(z: zz)", []string{"1:27: undefined: zz"}},
+		{"struct P { x }; P { x: y }", []string{"1:24: undefined: y"}},
+		{"foo(bar)", []string{"1:1: undefined: foo", "1:5: undefined: bar"}},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		_, errors, _ := resolver.Resolve(program)
+		checkErrors(t, tt.input, "errors", errors, tt.expectedErrors)
+	}
+}
+
+func TestResolveWarnings(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedWarnings []string
+	}{
+		{"let x = 1", []string{}},
+		{"fn(a) { 1 }", []string{"1:4: parameter a is not used"}},
+		{"fn(a, _) { a }", []string{}},
+		{"fn(...rest) { 1 }", []string{"1:7: parameter rest is not used"}},
+		{"fn() { let x = 1; 2 }", []string{"1:12: x declared but not used"}},
+		{"fn(a) { fn(b) { 1 } }", []string{"1:4: parameter a is not used", "1:12: parameter b is not used"}},
+		{"fn(xs) { let [a, b] = xs; a }", []string{"1:18: b declared but not used"}},
+		{"fn(x) { match (x) { n => 1 } }", []string{"1:21: n declared but not used"}},
+		{"fn() { try { 1 } catch (e) { 2 } }", []string{"1:25: e declared but not used"}},
+		{"fn(c) { select { recv(c) as v => 1 } }", []string{"1:29: v declared but not used"}},
+		{"struct P { x }; fn (p P) get() { 1 }", []string{}},
+		{"if (true) { let y = 1 }", []string{}},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		_, errors, warnings := resolver.Resolve(program)
+		if len(errors) != 0 {
+			t.Errorf("input %q: unexpected errors: %v", tt.input, errors)
+		}
+		checkErrors(t, tt.input, "warnings", warnings, tt.expectedWarnings)
+	}
+}
+
+func checkErrors(t *testing.T, input string, kind string, errors []*resolver.Error, expected []string) {
+	if len(errors) != len(expected) {
+		t.Errorf("input %q: wrong number of %s. want=%d, got=%d (%v)",
+			input, kind, len(expected), len(errors), errors)
+		return
+	}
+	for i, e := range errors {
+		if e.Error() != expected[i] {
+			t.Errorf("input %q: wrong %s %d. want=%q, got=%q", input, kind, i, expected[i], e.Error())
+		}
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("input %q: parser errors: %v", input, p.Errors())
+	}
+	return program
+}
+
+func TestCaptures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected [][]string // 関数リテラルごとの自由変数（出現順）
+	}{
+		{"fn(a) { a }", [][]string{{}}},
+		{"let x = 1; fn() { x }", [][]string{{}}},
+		{"fn(a) { fn() { a } }", [][]string{{}, {"a: local 0"}}},
+		{"fn(a, b) { fn() { b + a + b } }", [][]string{{}, {"b: local 1", "a: local 0"}}},
+		{"fn(a) { fn() { fn() { a } } }", [][]string{{}, {"a: local 0"}, {"a: free 0"}}},
+		{"fn(a) { fn(b) { fn() { b + a } } }",
+			[][]string{{}, {"a: local 0"}, {"b: local 0", "a: free 0"}}},
+		{"fn() { let f = fn(n) { f(n - 1) }; f }", [][]string{{}, {"f: local 0"}}},
+		{"fn(a) { fn(a) { a } }", [][]string{{}, {}}},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		info, errors, _ := resolver.Resolve(program)
+		if len(errors) != 0 {
+			t.Errorf("input %q: unexpected errors: %v", tt.input, errors)
+			continue
+		}
+
+		functions := []*ast.FunctionLiteral{}
+		ast.Walk(program, func(node ast.Node) bool {
+			if fn, ok := node.(*ast.FunctionLiteral); ok {
+				functions = append(functions, fn)
+			}
+			return true
+		})
+		if len(functions) != len(tt.expected) {
+			t.Fatalf("input %q: wrong number of functions. got=%d", tt.input, len(functions))
+		}
+
+		for i, fn := range functions {
+			captures, ok := info.Captures[fn]
+			if !ok {
+				t.Errorf("input %q: no captures recorded for function %d", tt.input, i)
+				continue
+			}
+			got := []string{}
+			for _, c := range captures {
+				got = append(got, c.String())
+			}
+			if len(got) != len(tt.expected[i]) {
+				t.Errorf("input %q: wrong captures of function %d. want=%v, got=%v",
+					tt.input, i, tt.expected[i], got)
+				continue
+			}
+			for j := range got {
+				if got[j] != tt.expected[i][j] {
+					t.Errorf("input %q: wrong capture %d of function %d. want=%q, got=%q",
+						tt.input, j, i, tt.expected[i][j], got[j])
+				}
+			}
+		}
+	}
+}
+
+func TestCapturedLocals(t *testing.T) {
+	program := parse(t, "fn(a, b) { let c = a; fn() { b + c } }")
+	info, _, _ := resolver.Resolve(program)
+
+	fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	expected := map[string]bool{"a": false, "b": true, "c": true}
+
+	for _, ident := range []*ast.Identifier{fn.Parameters[0], fn.Parameters[1], fn.Body.Statements[0].(*ast.LetStatement).Name} {
+		if info.Captured[ident] != expected[ident.Value] {
+			t.Errorf("Captured[%s] wrong. want=%t, got=%t", ident.Value, expected[ident.Value], info.Captured[ident])
+		}
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string // 末尾位置にある呼び出し（出現順）
+	}{
+		{"fn() { f() }", []string{"f()"}},
+		{"fn() { f(); g() }", []string{"g()"}},
+		{"fn() { f() + 1 }", []string{}},
+		{"fn() { g(f()) }", []string{"g(f())"}},
+		{"fn(n) { if (n < 1) { f() } else { g(n) } }", []string{"f()", "g(n)"}},
+		{"fn(n) { if (n < 1) { f() }; g() }", []string{"g()"}},
+		{"fn(n) { if (n < 1) { return f(); } g() }", []string{"f()", "g()"}},
+		{"fn(n) { match (n) { 0 => f(), _ => g(n) } }", []string{"f()", "g(n)"}},
+		{"fn(n) { n |> f }", []string{"(n |> f)"}},
+		{"fn() { try { f() } catch (e) { g(e) } }", []string{}},
+		{"fn() { try { return g() } finally { g() } }", []string{}},
+		{"fn() { try { 1 } catch (e) { return g(e) } }", []string{}},
+		{"fn() { try { fn() { return f() } } finally { 1 } }", []string{"f()"}},
+		{"fn() { try { 1 } finally { 2 }; return g() }", []string{"g()"}},
+		{"fn() { let x = f() }", []string{}},
+		{"fn() { fn() { f() }; g() }", []string{"f()", "g()"}},
+		{"f()", []string{}},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, "let f = fn() { 1 }; let g = fn(x = 1) { x }; "+tt.input)
+		info, errors, _ := resolver.Resolve(program)
+		if len(errors) != 0 {
+			t.Errorf("input %q: unexpected errors: %v", tt.input, errors)
+			continue
+		}
+
+		calls := []string{}
+		ast.Walk(program.Statements[2], func(node ast.Node) bool {
+			if exp, ok := node.(ast.Expression); ok && info.TailCalls[exp] {
+				calls = append(calls, exp.String())
+			}
+			return true
+		})
+
+		if len(calls) != len(tt.expected) {
+			t.Errorf("input %q: wrong tail calls. want=%v, got=%v", tt.input, tt.expected, calls)
+			continue
+		}
+		for i, call := range calls {
+			if call != tt.expected[i] {
+				t.Errorf("input %q: wrong tail call %d. want=%q, got=%q", tt.input, i, tt.expected[i], call)
+			}
+		}
+	}
+}
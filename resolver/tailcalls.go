@@ -0,0 +1,43 @@
+package resolver
+
+import (
+	"github.com/WTBacon/goInterpreter/ast"
+)
+
+/*
+	関数本体の末尾位置にある呼び出しを TailCalls に記録するメソッド.
+	末尾位置の呼び出しは, 呼び出し元のフレームを捨ててから実行できる.（末尾呼び出しの最適化）
+	以下が末尾位置になる.
+	- 関数本体の最後の式文
+	- 末尾位置にある if 式の両方の分岐, match 式の各アームの本体, ブロック文の最後の式文
+	- return 文の戻り値（try 式の中を除いて, 関数の中のどこにあっても末尾位置）
+	try 式の中は, 例外を捕まえるために呼び出し元のフレームが必要なので, return 文も含めて末尾位置にならない.
+ */
+func (r *resolver) tailBlock(b *ast.BlockStatement) {
+	if b == nil || len(b.Statements) == 0 {
+		return
+	}
+	switch s := b.Statements[len(b.Statements)-1].(type) {
+	case *ast.ExpressionStatement:
+		r.tailExpression(s.Expression)
+	case *ast.BlockStatement:
+		r.tailBlock(s)
+	}
+}
+
+/*
+	末尾位置にある式の中の, 末尾位置の呼び出しを記録するメソッド.
+ */
+func (r *resolver) tailExpression(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.CallExpression, *ast.PipeExpression:
+		r.info.TailCalls[exp] = true
+	case *ast.IfExpression:
+		r.tailBlock(exp.Consequence)
+		r.tailBlock(exp.Alternative)
+	case *ast.MatchExpression:
+		for _, arm := range exp.Arms {
+			r.tailExpression(arm.Body)
+		}
+	}
+}
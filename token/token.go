@@ -7,23 +7,41 @@ type TokenType string
 
 /*
 	Bacon 言語におけるトークンを表す構造体.
+	Line, Column	: ソースコード上でトークンが始まる位置（どちらも 1 始まり）
+	AfterNewline	: 直前のトークンとの間に改行があるか. 文の自動終端の判定に使う
  */
 type Token struct {
-	Type    TokenType
-	Literal string
+	Type         TokenType
+	Literal      string
+	Line         int
+	Column       int
+	AfterNewline bool
 }
 
 /*
 	Bacon 言語におけるキーワード.
  */
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":      FUNCTION,
+	"let":     LET,
+	"true":    TRUE,
+	"false":   FALSE,
+	"if":      IF,
+	"else":    ELSE,
+	"return":  RETURN,
+	"match":   MATCH,
+	"throw":   THROW,
+	"try":     TRY,
+	"catch":   CATCH,
+	"finally": FINALLY,
+	"import":  IMPORT,
+	"export":  EXPORT,
+	"as":      AS,
+	"macro":   MACRO,
+	"struct":  STRUCT,
+	"const":   CONST,
+	"spawn":   SPAWN,
+	"select":  SELECT,
 }
 
 /*
@@ -44,8 +62,14 @@ const (
 	EOF     = "EOF"
 
 	// 識別子 + リテラル
-	IDENT = "IDENT" // add, foobar, x, y, ...
-	INT   = "INT"   // 1234567
+	IDENT  = "IDENT"  // add, foobar, x, y, ...
+	INT    = "INT"    // 1234567
+	STRING = "STRING" // "path/to/mod"
+
+	// テンプレート文字列（ex. `Hello, ${name}!`）
+	BACKTICK      = "`"
+	TEMPLATE_TEXT = "TEMPLATE_TEXT" // Hello,
+	INTERPOLATION = "${"
 
 	// 演算子
 	ASSIGN   = "="
@@ -61,15 +85,28 @@ const (
 	EQ     = "=="
 	NOT_EQ = "!="
 
+	ARROW      = "=>"
+	PIPE       = "|>"
+	THIN_ARROW = "->"
+
+	RANGE           = ".."
+	RANGE_INCLUSIVE = "..="
+
 	// デリミタ
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
+	ELLIPSIS  = "..."
+	DOT       = "."
 
 	LPAREN = "("
 	RPAREN = ")"
 	LBRACE = "{"
 	RBRACE = "}"
 
+	LBRACKET = "["
+	RBRACKET = "]"
+
 	// キーワード
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
@@ -78,4 +115,17 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	MATCH    = "MATCH"
+	THROW    = "THROW"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
+	IMPORT   = "IMPORT"
+	EXPORT   = "EXPORT"
+	AS       = "AS"
+	MACRO    = "MACRO"
+	STRUCT   = "STRUCT"
+	CONST    = "CONST"
+	SPAWN    = "SPAWN"
+	SELECT   = "SELECT"
 )
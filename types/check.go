@@ -0,0 +1,955 @@
+package types
+
+import (
+	"fmt"
+	"github.com/WTBacon/goInterpreter/ast"
+	"github.com/WTBacon/goInterpreter/token"
+)
+
+/*
+	型検査で見つかったエラーを表す構造体型.
+	Line, Column	: エラーの原因になった式の位置
+	Message			: エラーの内容
+ */
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+/*
+	型検査の結果を表す構造体型.
+	Types	: 式から, その式に推論された型へのマップ
+ */
+type Info struct {
+	Types map[ast.Expression]Type
+}
+
+/*
+	式に推論された型を返すメソッド. 型検査していない式には nil を返す.
+ */
+func (info *Info) TypeOf(exp ast.Expression) Type {
+	t, ok := info.Types[exp]
+	if !ok {
+		return nil
+	}
+	return prune(t)
+}
+
+/*
+	プログラムの型を推論して, 型の誤りを検査する関数.
+	Hindley-Milner 型推論を段階的型付けに拡張したもので, 型注釈のない値にも型を推論するが,
+	静的に型が分からない値（import したモジュールのメンバーや組み込み関数など）は any として扱い,
+	any との組み合わせはエラーにしない. そのため, 報告するのは実行すれば必ず失敗する誤りだけになる.
+	let 文で束縛した関数リテラルは多相型になる（let 多相）.
+ */
+func Check(program *ast.Program) (*Info, []*Error) {
+	c := &checker{
+		info:    &Info{Types: map[ast.Expression]Type{}},
+		structs: map[string]*Struct{},
+	}
+
+	env := newEnv(nil)
+	c.declareStructs(program)
+	for _, s := range program.Statements {
+		c.statement(s, env)
+	}
+
+	return c.info, c.errors
+}
+
+/*
+	型環境（名前から型スキームへのマップ）を表す構造体型.
+ */
+type env struct {
+	outer *env
+	vars  map[string]*Scheme
+}
+
+func newEnv(outer *env) *env {
+	return &env{outer: outer, vars: map[string]*Scheme{}}
+}
+
+/*
+	外側の型環境を辿って, name の型スキームを探すメソッド.
+ */
+func (e *env) lookup(name string) (*Scheme, bool) {
+	for ; e != nil; e = e.outer {
+		if s, ok := e.vars[name]; ok {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+/*
+	型環境の中で, まだ決まっていない型変数の集合を返すメソッド.
+ */
+func (e *env) freeVars() map[*Var]bool {
+	free := map[*Var]bool{}
+	for ; e != nil; e = e.outer {
+		for _, s := range e.vars {
+			for _, v := range freeVars(s.Type) {
+				free[v] = true
+			}
+			for _, v := range s.Vars {
+				delete(free, v)
+			}
+		}
+	}
+	return free
+}
+
+/*
+	型検査の状態を表す構造体型.
+	info	: 推論した型
+	errors	: 見つかったエラー
+	structs	: 構造体型の名前から, 構造体型へのマップ
+	returns	: 検査中の関数の戻り値の型のスタック
+	trail	: 単一化で決めた型変数の履歴. 単一化を取り消すために使う
+	nextVar	: 次に作る型変数の番号
+ */
+type checker struct {
+	info    *Info
+	errors  []*Error
+	structs map[string]*Struct
+	returns []Type
+	trail   []*Var
+	nextVar int
+}
+
+/*
+	新しい型変数を作るメソッド.
+ */
+func (c *checker) fresh() *Var {
+	c.nextVar += 1
+	return &Var{id: c.nextVar}
+}
+
+/*
+	型 a と型 b を単一化するメソッド. 単一化できなければ false を返す.
+	Dynamic はどの型とも単一化できる.
+	関数の型は, 同じ数の実引数で呼び出せれば, 両方にある仮引数と戻り値の型を単一化する.
+ */
+func (c *checker) unify(a, b Type) bool {
+	a, b = prune(a), prune(b)
+	if a == b {
+		return true
+	}
+
+	if _, ok := a.(*Dynamic); ok {
+		return true
+	}
+	if _, ok := b.(*Dynamic); ok {
+		return true
+	}
+
+	if v, ok := a.(*Var); ok {
+		if occurs(v, b) {
+			return false
+		}
+		v.instance = b
+		c.trail = append(c.trail, v)
+		return true
+	}
+	if _, ok := b.(*Var); ok {
+		return c.unify(b, a)
+	}
+
+	switch a := a.(type) {
+	case *Func:
+		b, ok := b.(*Func)
+		if !ok || !arityOverlaps(a, b) {
+			return false
+		}
+		for i := 0; i < len(a.Params) && i < len(b.Params); i++ {
+			if !c.unify(a.Params[i], b.Params[i]) {
+				return false
+			}
+		}
+		return c.unify(a.Return, b.Return)
+	case *Struct:
+		b, ok := b.(*Struct)
+		return ok && a.Name == b.Name
+	}
+	// Basic は名前ごとに 1 つしかないので, ポインタが異なれば別の型.
+	return false
+}
+
+/*
+	関数 a と関数 b を, 同じ数の実引数で呼び出せるか判定する関数.
+	デフォルト値を持つ仮引数や残余引数があるので, 仮引数の数が違っても同じ関数として使えることがある.
+	（ex. fn(x, y = 2) は, fn(int) -> int としても fn(int, int) -> int としても呼び出せる）
+ */
+func arityOverlaps(a, b *Func) bool {
+	least := a.Required
+	if b.Required > least {
+		least = b.Required
+	}
+	return (a.Variadic || least <= len(a.Params)) && (b.Variadic || least <= len(b.Params))
+}
+
+/*
+	型 a と型 b を単一化できればその型を, できなければ Dynamic を返すメソッド.
+	if 式の分岐のように, 実行時に型が混ざってもよい箇所で使う.
+ */
+func (c *checker) join(a, b Type) Type {
+	mark := len(c.trail)
+	if c.unify(a, b) {
+		return a
+	}
+	for _, v := range c.trail[mark:] {
+		v.instance = nil
+	}
+	c.trail = c.trail[:mark]
+	return Any
+}
+
+/*
+	型スキームの量化された型変数を, 新しい型変数に置き換えるメソッド.
+ */
+func (c *checker) instantiate(s *Scheme) Type {
+	if len(s.Vars) == 0 {
+		return s.Type
+	}
+	mapping := map[*Var]Type{}
+	for _, v := range s.Vars {
+		mapping[v] = c.fresh()
+	}
+	return substitute(s.Type, mapping)
+}
+
+/*
+	型 t のうち, 型環境 e で決まっていない型変数を量化した型スキームを返す関数.
+ */
+func generalize(t Type, e *env) *Scheme {
+	envVars := e.freeVars()
+	seen := map[*Var]bool{}
+	vars := []*Var{}
+	for _, v := range freeVars(t) {
+		if !envVars[v] && !seen[v] {
+			seen[v] = true
+			vars = append(vars, v)
+		}
+	}
+	return &Scheme{Vars: vars, Type: t}
+}
+
+/*
+	ソースコード上の位置を付けたエラーを追加するメソッド.
+ */
+func (c *checker) errorf(tok token.Token, format string, args ...interface{}) {
+	c.errors = append(c.errors, &Error{
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+/*
+	プログラムの構造体型とメソッドを, 型検査の前に登録するメソッド.
+	構造体型とメソッドは, 宣言より前でも使える.
+ */
+func (c *checker) declareStructs(program *ast.Program) {
+	for _, s := range program.Statements {
+		if stmt, ok := s.(*ast.StructStatement); ok {
+			st := &Struct{Name: stmt.Name.Value, Methods: map[string]Type{}}
+			for _, f := range stmt.Fields {
+				st.Fields = append(st.Fields, f.Value)
+			}
+			c.structs[st.Name] = st
+		}
+	}
+
+	// メソッドは式文としても, let 文の値としても書ける.
+	ast.Walk(program, func(node ast.Node) bool {
+		fn, ok := node.(*ast.FunctionLiteral)
+		if !ok || fn.Receiver == nil {
+			return true
+		}
+		if st, ok := c.structs[fn.Receiver.Type.Value]; ok {
+			st.Methods[fn.Name.Value] = c.fresh()
+		}
+		return true
+	})
+}
+
+/*
+	型注釈が表す型を返すメソッド.
+ */
+func (c *checker) annotation(typ ast.TypeAnnotation) Type {
+	switch typ := typ.(type) {
+	case *ast.NamedType:
+		switch typ.Name {
+		case "int":
+			return Int
+		case "bool":
+			return Bool
+		case "string":
+			return String
+		case "range":
+			return Range
+		case "any":
+			return Any
+		}
+		if st, ok := c.structs[typ.Name]; ok {
+			return st
+		}
+		c.errorf(typ.Token, "unknown type %s", typ.Name)
+		return Any
+	case *ast.FunctionType:
+		fn := &Func{Params: []Type{}, Return: Any}
+		for _, p := range typ.Parameters {
+			fn.Params = append(fn.Params, c.annotation(p))
+		}
+		fn.Required = len(fn.Params)
+		if typ.Return != nil {
+			fn.Return = c.annotation(typ.Return)
+		}
+		return fn
+	}
+	return Any
+}
+
+/*
+	文の型を推論するメソッド. 式文ならその式の型を, それ以外の文なら Dynamic を返す.
+ */
+func (c *checker) statement(s ast.Statement, e *env) Type {
+	switch s := s.(type) {
+	case *ast.ExpressionStatement:
+		return c.expression(s.Expression, e)
+
+	case *ast.LetStatement:
+		c.letStatement(s, e)
+
+	case *ast.ConstStatement:
+		c.bind(s.Name, s.Value, nil, e)
+
+	case *ast.ReturnStatement:
+		t := Type(Any)
+		if s.ReturnValue != nil {
+			t = c.expression(s.ReturnValue, e)
+		}
+		if len(c.returns) > 0 {
+			want := c.returns[len(c.returns)-1]
+			if !c.unify(t, want) {
+				c.errorf(s.Token, "cannot use %s (type %s) as type %s in return",
+					s.ReturnValue.String(), t, want)
+			}
+		}
+
+	case *ast.ThrowStatement:
+		c.expression(s.Value, e)
+
+	case *ast.ImportStatement:
+		e.vars[s.Alias.Value] = &Scheme{Type: Any}
+
+	case *ast.ExportStatement:
+		c.letStatement(s.Statement, e)
+
+	case *ast.BlockStatement:
+		return c.block(s, newEnv(e))
+	}
+	return Any
+}
+
+/*
+	let 文の型を推論して, 束縛する名前を型環境に追加するメソッド.
+ */
+func (c *checker) letStatement(s *ast.LetStatement, e *env) {
+	if s.Pattern != nil {
+		// 分割代入される値の要素の型は分からない.
+		c.expression(s.Value, e)
+		for _, ident := range ast.PatternIdentifiers(s.Pattern) {
+			e.vars[ident.Value] = &Scheme{Type: Any}
+		}
+		return
+	}
+	c.bind(s.Name, s.Value, s.Type, e)
+}
+
+/*
+	name に value を束縛して, 型環境に追加するメソッド.
+	関数リテラルは自分自身を再帰的に呼び出せるように, 推論の前に名前を型変数に束縛しておき,
+	推論した型を多相型にする.
+ */
+func (c *checker) bind(name *ast.Identifier, value ast.Expression, typ ast.TypeAnnotation, e *env) {
+	var declared Type
+	if typ != nil {
+		declared = c.annotation(typ)
+	}
+
+	_, isFunction := value.(*ast.FunctionLiteral)
+
+	var t Type
+	if isFunction {
+		self := Type(c.fresh())
+		if declared != nil {
+			self = declared
+		}
+		e.vars[name.Value] = &Scheme{Type: self}
+		t = c.expression(value, e)
+		c.unify(self, t)
+	} else {
+		t = c.expression(value, e)
+	}
+
+	if declared != nil {
+		if !c.unify(t, declared) {
+			c.errorf(tokenOf(value), "cannot use %s (type %s) as type %s in assignment to %s",
+				value.String(), t, declared, name.Value)
+		}
+		t = declared
+	}
+
+	if isFunction {
+		delete(e.vars, name.Value)
+		e.vars[name.Value] = generalize(t, e)
+		return
+	}
+	e.vars[name.Value] = &Scheme{Type: t}
+}
+
+/*
+	ブロックの文を型環境 e で推論して, 最後の文の型を返すメソッド.
+ */
+func (c *checker) block(b *ast.BlockStatement, e *env) Type {
+	t := Type(Any)
+	if b == nil {
+		return t
+	}
+	for _, s := range b.Statements {
+		t = c.statement(s, e)
+	}
+	return t
+}
+
+/*
+	式の型を推論するメソッド. 推論した型は Info に記録する.
+ */
+func (c *checker) expression(exp ast.Expression, e *env) Type {
+	t := c.infer(exp, e)
+	c.info.Types[exp] = t
+	return t
+}
+
+func (c *checker) infer(exp ast.Expression, e *env) Type {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return Int
+
+	case *ast.Boolean:
+		return Bool
+
+	case *ast.TemplateLiteral:
+		for _, part := range exp.Parts {
+			c.expression(part, e)
+		}
+		return String
+
+	case *ast.TemplateText:
+		return String
+
+	case *ast.Identifier:
+		if s, ok := e.lookup(exp.Value); ok {
+			return c.instantiate(s)
+		}
+		// 組み込み関数や, 未定義の名前.
+		return Any
+
+	case *ast.PrefixExpression:
+		right := c.expression(exp.Right, e)
+		if exp.Operator == "-" {
+			if !c.unify(right, Int) {
+				c.errorf(exp.Token, "invalid operation: operator - not defined on %s (type %s)",
+					exp.Right.String(), right)
+			}
+			return Int
+		}
+		return Bool
+
+	case *ast.InfixExpression:
+		return c.infix(exp, e)
+
+	case *ast.IfExpression:
+		c.expression(exp.Condition, e)
+		consequence := c.block(exp.Consequence, newEnv(e))
+		if exp.Alternative == nil {
+			return Any
+		}
+		alternative := c.block(exp.Alternative, newEnv(e))
+		return c.join(consequence, alternative)
+
+	case *ast.FunctionLiteral:
+		return c.function(exp, e)
+
+	case *ast.CallExpression:
+		return c.call(exp, e)
+
+	case *ast.SpreadExpression:
+		c.expression(exp.Value, e)
+		return Any
+
+	case *ast.PipeExpression:
+		return c.expression(exp.Call(), e)
+
+	case *ast.MatchExpression:
+		return c.match(exp, e)
+
+	case *ast.SpawnExpression:
+		// 呼び出しの結果は, spawn した側からは受け取れない.
+		c.expression(exp.Call, e)
+		return Any
+
+	case *ast.SelectExpression:
+		var t Type
+		for _, sc := range exp.Cases {
+			caseEnv := newEnv(e)
+			if sc.Comm != nil {
+				c.expression(sc.Comm, e)
+			}
+			if sc.Value != nil {
+				caseEnv.vars[sc.Value.Value] = &Scheme{Type: Any}
+			}
+			body := c.expression(sc.Body, caseEnv)
+			if t == nil {
+				t = body
+			} else {
+				t = c.join(t, body)
+			}
+		}
+		if t == nil {
+			return Any
+		}
+		return t
+
+	case *ast.TryExpression:
+		t := c.block(exp.Block, newEnv(e))
+		if exp.Catch != nil {
+			catchEnv := newEnv(e)
+			if exp.CatchParam != nil {
+				catchEnv.vars[exp.CatchParam.Value] = &Scheme{Type: Any}
+			}
+			t = c.join(t, c.block(exp.Catch, catchEnv))
+		}
+		if exp.Finally != nil {
+			c.block(exp.Finally, newEnv(e))
+		}
+		return t
+
+	case *ast.MemberExpression:
+		return c.member(exp, e)
+
+	case *ast.StructLiteral:
+		for _, f := range exp.Fields {
+			c.expression(f.Value, e)
+		}
+		if st, ok := c.structs[exp.Name.Value]; ok {
+			return st
+		}
+		return Any
+
+	case *ast.RangeExpression:
+		c.expectInt(exp.Start, c.expression(exp.Start, e), "range bound")
+		c.expectInt(exp.End, c.expression(exp.End, e), "range bound")
+		return Range
+
+	case *ast.IndexExpression:
+		left := c.expression(exp.Left, e)
+		c.expectInt(exp.Index, c.expression(exp.Index, e), "index")
+		switch prune(left) {
+		case String:
+			return String
+		case Range:
+			return Int
+		case Int, Bool:
+			c.errorf(tokenOf(exp.Left), "cannot index %s (type %s)", exp.Left.String(), left)
+		}
+		if _, ok := prune(left).(*Func); ok {
+			c.errorf(tokenOf(exp.Left), "cannot index %s (type %s)", exp.Left.String(), left)
+		}
+		return Any
+
+	case *ast.SliceExpression:
+		left := c.expression(exp.Left, e)
+		for _, bound := range []ast.Expression{exp.Start, exp.End, exp.Step} {
+			if bound != nil {
+				c.expectInt(bound, c.expression(bound, e), "slice index")
+			}
+		}
+		switch prune(left) {
+		case String, Range:
+			return prune(left)
+		case Int, Bool:
+			c.errorf(tokenOf(exp.Left), "cannot slice %s (type %s)", exp.Left.String(), left)
+		}
+		if _, ok := prune(left).(*Func); ok {
+			c.errorf(tokenOf(exp.Left), "cannot slice %s (type %s)", exp.Left.String(), left)
+		}
+		return Any
+	}
+
+	// マクロリテラルなど, 値の型が静的に分からない式.
+	return Any
+}
+
+/*
+	exp の型 t が int であることを検査するメソッド.
+ */
+func (c *checker) expectInt(exp ast.Expression, t Type, context string) {
+	if !c.unify(t, Int) {
+		c.errorf(tokenOf(exp), "cannot use %s (type %s) as type int in %s", exp.String(), t, context)
+	}
+}
+
+/*
+	中置演算子を含む式の型を推論するメソッド.
+	'+' は両辺が同じ型の int か string でなければならない. どちらかが string なら両辺を string に,
+	そうでなければ int にする. ただし両辺とも型がまだ分からない場合は, 加算か連結か決められないので,
+	両辺が同じ型であることだけを検査して, その型を結果の型にする.（ex. fn(a, b) { a + b } は fn(t, t) -> t）
+	'-', '*', '/', '<', '>' は両辺が int でなければならない.
+	'==' と '!=' は異なる型の値も比較できる.
+ */
+func (c *checker) infix(exp *ast.InfixExpression, e *env) Type {
+	left := c.expression(exp.Left, e)
+	right := c.expression(exp.Right, e)
+
+	switch exp.Operator {
+	case "==", "!=":
+		return Bool
+	case "+", "-", "*", "/", "<", ">":
+	default:
+		return Any
+	}
+
+	if exp.Operator == "+" && isUnknown(left) && isUnknown(right) {
+		c.unify(left, right)
+		return left
+	}
+
+	want := Type(Int)
+	if exp.Operator == "+" && (prune(left) == String || prune(right) == String) {
+		want = String
+	}
+
+	l, r := prune(left), prune(right)
+	_, lBasic := l.(*Basic)
+	_, rBasic := r.(*Basic)
+	if lBasic && rBasic && l != r {
+		c.errorf(exp.Token, "invalid operation: %s (mismatched types %s and %s)", exp.String(), l, r)
+	} else {
+		for _, operand := range []struct {
+			exp ast.Expression
+			t   Type
+		}{{exp.Left, left}, {exp.Right, right}} {
+			if !c.unify(operand.t, want) {
+				c.errorf(tokenOf(operand.exp), "invalid operation: operator %s not defined on %s (type %s)",
+					exp.Operator, operand.exp.String(), operand.t)
+				break
+			}
+		}
+	}
+
+	switch exp.Operator {
+	case "<", ">":
+		return Bool
+	}
+	return want
+}
+
+/*
+	型がまだ分からない（型変数か Dynamic）か判定する関数.
+ */
+func isUnknown(t Type) bool {
+	switch prune(t).(type) {
+	case *Var, *Dynamic:
+		return true
+	}
+	return false
+}
+
+/*
+	関数リテラルの型を推論するメソッド.
+	仮引数と関数本体は同じスコープにある. 型注釈のない仮引数や戻り値には型変数を割り当てる.
+ */
+func (c *checker) function(fn *ast.FunctionLiteral, e *env) Type {
+	fnEnv := newEnv(e)
+
+	if fn.Receiver != nil {
+		receiver := Type(Any)
+		if st, ok := c.structs[fn.Receiver.Type.Value]; ok {
+			receiver = st
+		}
+		fnEnv.vars[fn.Receiver.Name.Value] = &Scheme{Type: receiver}
+	}
+
+	t := &Func{Params: []Type{}, Names: []string{}, Variadic: fn.Rest != nil}
+	for _, p := range fn.Parameters {
+		var param Type = c.fresh()
+		if typ, ok := fn.Types[p.Value]; ok {
+			param = c.annotation(typ)
+		}
+
+		if def, ok := fn.Defaults[p.Value]; ok {
+			d := c.expression(def, e)
+			if !c.unify(d, param) {
+				c.errorf(tokenOf(def), "cannot use %s (type %s) as type %s in default value of %s",
+					def.String(), d, param, p.Value)
+			}
+		} else {
+			t.Required += 1
+		}
+
+		fnEnv.vars[p.Value] = &Scheme{Type: param}
+		t.Params = append(t.Params, param)
+		t.Names = append(t.Names, p.Value)
+	}
+	if fn.Rest != nil {
+		fnEnv.vars[fn.Rest.Value] = &Scheme{Type: Any}
+	}
+
+	t.Return = c.fresh()
+	if fn.ReturnType != nil {
+		t.Return = c.annotation(fn.ReturnType)
+	}
+
+	c.returns = append(c.returns, t.Return)
+	body := c.block(fn.Body, fnEnv)
+	c.returns = c.returns[:len(c.returns)-1]
+
+	// 関数本体の最後の式の値が, 暗黙の戻り値になる.
+	if n := len(fn.Body.Statements); n > 0 {
+		if last, ok := fn.Body.Statements[n-1].(*ast.ExpressionStatement); ok {
+			if !c.unify(body, t.Return) {
+				c.errorf(tokenOf(last.Expression), "cannot use %s (type %s) as type %s in return",
+					last.Expression.String(), body, t.Return)
+			}
+		}
+	}
+
+	if fn.Receiver != nil {
+		if st, ok := c.structs[fn.Receiver.Type.Value]; ok {
+			c.unify(st.Methods[fn.Name.Value], t)
+		}
+	}
+
+	return t
+}
+
+/*
+	関数呼び出し式の型を推論するメソッド.
+	呼び出される関数の型が分かっていれば, 実引数の数と型を検査する.
+ */
+func (c *checker) call(call *ast.CallExpression, e *env) Type {
+	function := c.expression(call.Function, e)
+
+	args := []Type{}
+	spread := false
+	for _, a := range call.Arguments {
+		args = append(args, c.expression(a, e))
+		if _, ok := a.(*ast.SpreadExpression); ok {
+			spread = true
+		}
+	}
+	named := map[string]Type{}
+	for _, a := range call.NamedArguments {
+		named[a.Name.Value] = c.expression(a.Value, e)
+	}
+
+	name := call.Function.String()
+
+	switch fn := prune(function).(type) {
+	case *Func:
+		if spread {
+			// 展開される配列の長さは分からない.
+			return fn.Return
+		}
+		if len(args) > len(fn.Params) && !fn.Variadic {
+			c.errorf(call.Token, "too many arguments in call to %s (want %d, got %d)",
+				name, len(fn.Params), len(args))
+			return fn.Return
+		}
+		for i, a := range args {
+			if i >= len(fn.Params) {
+				break
+			}
+			if !c.unify(a, fn.Params[i]) {
+				c.errorf(tokenOf(call.Arguments[i]), "cannot use %s (type %s) as type %s in argument to %s",
+					call.Arguments[i].String(), a, fn.Params[i], name)
+			}
+		}
+		for _, a := range call.NamedArguments {
+			i := indexOf(fn.Names, a.Name.Value)
+			if i < 0 {
+				if fn.Names != nil {
+					c.errorf(a.Token, "unknown parameter %s in call to %s", a.Name.Value, name)
+				}
+				continue
+			}
+			if i < len(args) {
+				c.errorf(a.Token, "parameter %s of %s is given twice", a.Name.Value, name)
+				continue
+			}
+			if !c.unify(named[a.Name.Value], fn.Params[i]) {
+				c.errorf(tokenOf(a.Value), "cannot use %s (type %s) as type %s in argument to %s",
+					a.Value.String(), named[a.Name.Value], fn.Params[i], name)
+			}
+		}
+		for i := len(args); i < fn.Required; i++ {
+			if fn.Names == nil {
+				c.errorf(call.Token, "not enough arguments in call to %s (want %d, got %d)",
+					name, fn.Required, len(args))
+				break
+			}
+			if _, ok := named[fn.Names[i]]; !ok {
+				c.errorf(call.Token, "not enough arguments in call to %s: missing %s", name, fn.Names[i])
+				break
+			}
+		}
+		return fn.Return
+
+	case *Var:
+		if spread || len(call.NamedArguments) != 0 {
+			return Any
+		}
+		ret := c.fresh()
+		c.unify(fn, &Func{Params: args, Required: len(args), Return: ret})
+		return ret
+
+	case *Dynamic:
+		return Any
+	}
+
+	c.errorf(tokenOf(call.Function), "cannot call non-function %s (type %s)", name, function)
+	return Any
+}
+
+/*
+	メンバーアクセス式の型を推論するメソッド.
+	構造体のフィールドの型は分からないので any になり, メソッドはその型になる.
+ */
+func (c *checker) member(exp *ast.MemberExpression, e *env) Type {
+	object := c.expression(exp.Object, e)
+
+	switch obj := prune(object).(type) {
+	case *Struct:
+		if obj.hasField(exp.Property.Value) {
+			return Any
+		}
+		if method, ok := obj.Methods[exp.Property.Value]; ok {
+			return method
+		}
+		c.errorf(exp.Property.Token, "%s has no field or method %s", obj.Name, exp.Property.Value)
+	case *Basic, *Func:
+		c.errorf(exp.Property.Token, "%s (type %s) has no field or method %s",
+			exp.Object.String(), obj, exp.Property.Value)
+	}
+	return Any
+}
+
+/*
+	match 式の型を推論するメソッド.
+	識別子のパターンは検査される値の型になり, それ以外のパターンが束縛する名前は any になる.
+ */
+func (c *checker) match(exp *ast.MatchExpression, e *env) Type {
+	subject := c.expression(exp.Subject, e)
+
+	var t Type
+	for _, arm := range exp.Arms {
+		armEnv := newEnv(e)
+		switch pattern := arm.Pattern.(type) {
+		case *ast.Identifier:
+			armEnv.vars[pattern.Value] = &Scheme{Type: subject}
+		case *ast.LiteralPattern:
+			c.expression(pattern.Value, e)
+		default:
+			for _, ident := range ast.PatternIdentifiers(pattern) {
+				armEnv.vars[ident.Value] = &Scheme{Type: Any}
+			}
+		}
+		if arm.Guard != nil {
+			c.expression(arm.Guard, armEnv)
+		}
+
+		body := c.expression(arm.Body, armEnv)
+		if t == nil {
+			t = body
+		} else {
+			t = c.join(t, body)
+		}
+	}
+
+	if t == nil {
+		return Any
+	}
+	return t
+}
+
+/*
+	式の最初のトークンを返すヘルパー関数. エラーの位置に使う.
+ */
+func tokenOf(exp ast.Expression) token.Token {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		return exp.Token
+	case *ast.IntegerLiteral:
+		return exp.Token
+	case *ast.Boolean:
+		return exp.Token
+	case *ast.PrefixExpression:
+		return exp.Token
+	case *ast.InfixExpression:
+		return tokenOf(exp.Left)
+	case *ast.IfExpression:
+		return exp.Token
+	case *ast.FunctionLiteral:
+		return exp.Token
+	case *ast.CallExpression:
+		return tokenOf(exp.Function)
+	case *ast.SpreadExpression:
+		return exp.Token
+	case *ast.MatchExpression:
+		return exp.Token
+	case *ast.SpawnExpression:
+		return exp.Token
+	case *ast.SelectExpression:
+		return exp.Token
+	case *ast.PipeExpression:
+		return tokenOf(exp.Left)
+	case *ast.TryExpression:
+		return exp.Token
+	case *ast.MemberExpression:
+		return tokenOf(exp.Object)
+	case *ast.MacroLiteral:
+		return exp.Token
+	case *ast.StructLiteral:
+		return exp.Name.Token
+	case *ast.TemplateLiteral:
+		return exp.Token
+	case *ast.TemplateText:
+		return exp.Token
+	case *ast.RangeExpression:
+		return tokenOf(exp.Start)
+	case *ast.IndexExpression:
+		return tokenOf(exp.Left)
+	case *ast.SliceExpression:
+		return tokenOf(exp.Left)
+	}
+	return token.Token{}
+}
+
+/*
+	names の中の name の添字を返すヘルパー関数. なければ -1 を返す.
+ */
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
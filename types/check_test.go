@@ -0,0 +1,148 @@
+package types
+
+import (
+	"github.com/WTBacon/goInterpreter/ast"
+	"github.com/WTBacon/goInterpreter/lexer"
+	"github.com/WTBacon/goInterpreter/parser"
+	"testing"
+)
+
+func TestInferredTypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5", "int"},
+		{"true", "bool"},
+		{"-5 * 2", "int"},
+		{"!5", "bool"},
+		{"1 < 2", "bool"},
+		{"1 == true", "bool"},
+		{"`a ${1}`", "string"},
+		{"`a` + `b`", "string"},
+		{"fn(x) { x + 1 }", "fn(int) -> int"},
+		{"fn(x, y) { x < y }", "fn(int, int) -> bool"},
+		{"fn(a, b) { a + b }", "fn(t3, t3) -> t3"},
+		{"let concat = fn(a, b) { a + b }; concat(`x`, `y`)", "string"},
+		{"let concat = fn(a, b) { a + b }; concat(1, 2)", "int"},
+		{"fn(x: int, y: int) -> int { x }", "fn(int, int) -> int"},
+		{"fn(f, x) { f(x) + 1 }", "fn(fn(t2) -> int, t2) -> int"},
+		{"let id = fn(x) { x }; id(1)", "int"},
+		{"let id = fn(x) { x }; id(true)", "bool"},
+		{"let f = fn(n) { if (n < 1) { 0 } else { f(n - 1) } }; f", "fn(int) -> int"},
+		{"if (x) { 1 } else { true }", "any"},
+		{"if (x) { 1 } else { 2 }", "int"},
+		{"0..10", "range"},
+		{"(0..10)[1]", "int"},
+		{"`abc`[1:]", "string"},
+		{"len(xs)", "any"},
+		{"struct P { x }; P { x: 1 }", "P"},
+		{"struct P { x }; fn (p P) get(n: int) { n }; P { x: 1 }.get(2)", "int"},
+		{"match (1) { 0 => 1, n => n + 1 }", "int"},
+		{"let f = fn(x, y = 2) { x + y }; f(1)", "int"},
+		{"5 |> fn(x) { x < 3 }", "bool"},
+		{"select { recv(c) => 1, _ => 2 }", "int"},
+		{"select { recv(c) as v => v, _ => 2 }", "any"},
+		{"spawn len(1)", "any"},
+		{"let f = fn(x, y = 2) { x + y }; let apply = fn(g, v) { g(v) }; apply(f, 1)", "int"},
+		{"let f = fn(x, ...xs) { x }; let apply = fn(g) { g(1, 2, 3) }; apply(f)", "int"},
+		{"let f = fn(x = 1) { x }; let call = fn(g: fn() -> int) { g() }; call(f)", "int"},
+		{"struct P { x }; let get = fn (p P) get() { 1 }; P { x: 1 }.get()", "int"},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		info, errors := Check(program)
+		if len(errors) != 0 {
+			t.Errorf("input %q: unexpected type errors: %v", tt.input, errors)
+			continue
+		}
+
+		last := program.Statements[len(program.Statements)-1].(*ast.ExpressionStatement)
+		typ := info.TypeOf(last.Expression)
+		if typ == nil {
+			t.Errorf("input %q: no type recorded", tt.input)
+			continue
+		}
+		if typ.String() != tt.expected {
+			t.Errorf("input %q: wrong type. want=%q, got=%q", tt.input, tt.expected, typ.String())
+		}
+	}
+}
+
+func TestTypeErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"true + 1", "1:6: invalid operation: (true + 1) (mismatched types bool and int)"},
+		{"`a` + 1", "1:5: invalid operation: (`a` + 1) (mismatched types string and int)"},
+		{"-true", "1:1: invalid operation: operator - not defined on true (type bool)"},
+		{"let x = 5; x(1)", "1:12: cannot call non-function x (type int)"},
+		{"let x: bool = 5", "1:15: cannot use 5 (type int) as type bool in assignment to x"},
+		{"let x: foo = 5", "1:8: unknown type foo"},
+		{"fn(x: int) -> bool { x }", "1:22: cannot use x (type int) as type bool in return"},
+		{"fn(x) -> int { return true; }", "1:16: cannot use true (type bool) as type int in return"},
+		{"let f = fn(x: int) { x }; f(true)", "1:29: cannot use true (type bool) as type int in argument to f"},
+		{"let f = fn(x) { x + 1 }; f(1, 2)", "1:27: too many arguments in call to f (want 1, got 2)"},
+		{"let f = fn(x, y) { x }; f(1)", "1:26: not enough arguments in call to f: missing y"},
+		{"let f = fn(x, y) { x }; f(1, z: 2)", "1:30: unknown parameter z in call to f"},
+		{"let f = fn(x: int = true) { x }", "1:21: cannot use true (type bool) as type int in default value of x"},
+		{"let f = fn(g) { g(1) + g(true) }", "1:26: cannot use true (type bool) as type int in argument to g"},
+		{"let x = 1; x.y", "1:14: x (type int) has no field or method y"},
+		{"struct P { x }; P { x: 1 }.z", "1:28: P has no field or method z"},
+		{"struct P { x }; fn (p P) get() -> int { true }", "1:41: cannot use true (type bool) as type int in return"},
+		{"0..true", "1:4: cannot use true (type bool) as type int in range bound"},
+		{"let n = 5; n[0]", "1:12: cannot index n (type int)"},
+		{"`abc`[true]", "1:7: cannot use true (type bool) as type int in index"},
+		{"let f = fn(x) { x }; f.y", "1:24: f (type fn(t4) -> t4) has no field or method y"},
+		{"let add = fn(a, b) { a + b }; add(1, `x`)",
+			"1:38: cannot use `x` (type string) as type int in argument to add"},
+		{"let f = fn(x, y) { x }; let apply = fn(g) { g(1) }; apply(f)",
+			"1:59: cannot use f (type fn(t10, t11) -> t10) as type fn(int) -> t9 in argument to apply"},
+		{"let xs: fn(int) -> int = fn(x: bool) { x }",
+			"1:26: cannot use fn(x: bool) x (type fn(bool) -> bool) as type fn(int) -> int in assignment to xs"},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		_, errors := Check(program)
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected type errors, got none", tt.input)
+			continue
+		}
+		if errors[0].Error() != tt.expectedError {
+			t.Errorf("input %q: wrong error. want=%q, got=%q",
+				tt.input, tt.expectedError, errors[0].Error())
+		}
+	}
+}
+
+func TestGradualTyping(t *testing.T) {
+	tests := []string{
+		`import "m" as m; m.value + 1`,
+		`let f = fn(x: any) { x + 1 }; f(true)`,
+		`let xs = len(ys); xs(1)(true)`,
+		`let [a, b] = pair; a + b; a(1)`,
+		`let f = fn(...xs) { xs }; f(1, true, 3)`,
+		`try { 1 } catch (e) { e + 1 }`,
+		`match (x) { [a] => a + 1, {b} => b(true) }`,
+		`let f = fn(x) { x }; f(...xs)`,
+	}
+
+	for _, input := range tests {
+		program := parse(t, input)
+		if _, errors := Check(program); len(errors) != 0 {
+			t.Errorf("input %q: unexpected type errors: %v", input, errors)
+		}
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("input %q: parser errors: %v", input, p.Errors())
+	}
+	return program
+}
@@ -0,0 +1,202 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+	静的型検査で扱う Bacon の型を表すインターフェース.
+	Basic, Dynamic, Var, Func, Struct が実装する.
+ */
+type Type interface {
+	String() string
+}
+
+/*
+	組み込みの型を表す構造体型.（ex. int / bool / string / range）
+	同じ名前の Basic は 1 つしか作らないので, ポインタで比較できる.
+ */
+type Basic struct {
+	Name string
+}
+
+func (b *Basic) String() string { return b.Name }
+
+/*
+	組み込みの型.
+ */
+var (
+	Int    = &Basic{Name: "int"}
+	Bool   = &Basic{Name: "bool"}
+	String = &Basic{Name: "string"}
+	Range  = &Basic{Name: "range"}
+)
+
+/*
+	静的には型が分からない値の型を表す構造体型.（型注釈では any と書く）
+	段階的型付け（gradual typing）のために, Dynamic はどの型とも単一化できる.
+ */
+type Dynamic struct{}
+
+func (d *Dynamic) String() string { return "any" }
+
+/*
+	型が分からない値の型.
+ */
+var Any = &Dynamic{}
+
+/*
+	型推論の途中で, まだ決まっていない型を表す型変数.
+	id			: 型変数の番号（表示用）
+	instance	: 単一化によって決まった型. まだ決まっていなければ nil
+ */
+type Var struct {
+	id       int
+	instance Type
+}
+
+func (v *Var) String() string {
+	if v.instance != nil {
+		return v.instance.String()
+	}
+	return fmt.Sprintf("t%d", v.id)
+}
+
+/*
+	関数の型を表す構造体型.（ex. fn(int, int) -> int）
+	Params		: 仮引数の型
+	Names		: 仮引数の名前（キーワード引数の検査に使う）. 型注釈から作った関数の型では nil
+	Required	: デフォルト値を持たない仮引数の数
+	Variadic	: 残余引数を持つか
+	Return		: 戻り値の型
+ */
+type Func struct {
+	Params   []Type
+	Names    []string
+	Required int
+	Variadic bool
+	Return   Type
+}
+
+func (f *Func) String() string {
+	params := []string{}
+	for _, p := range f.Params {
+		params = append(params, p.String())
+	}
+	if f.Variadic {
+		params = append(params, "...any")
+	}
+	return "fn(" + strings.Join(params, ", ") + ") -> " + f.Return.String()
+}
+
+/*
+	struct 文で宣言された構造体型を表す構造体型.
+	Name	: 構造体型の名前
+	Fields	: フィールド名. フィールドの値の型は静的には分からないので, 全て any として扱う
+	Methods	: メソッド名から, メソッドの型（レシーバを除く）へのマップ
+ */
+type Struct struct {
+	Name    string
+	Fields  []string
+	Methods map[string]Type
+}
+
+func (s *Struct) String() string { return s.Name }
+
+/*
+	構造体型が name という名前のフィールドを持つか判定するメソッド.
+ */
+func (s *Struct) hasField(name string) bool {
+	for _, f := range s.Fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	型スキーム（多相型）を表す構造体型.（ex. let id = fn(x) { x } の ∀t1. fn(t1) -> t1）
+	Vars	: 量化された型変数. 参照されるたびに新しい型変数に置き換える
+	Type	: 型
+ */
+type Scheme struct {
+	Vars []*Var
+	Type Type
+}
+
+/*
+	単一化によって決まった型変数を辿って, 型変数でない型か, まだ決まっていない型変数を返す関数.
+ */
+func prune(t Type) Type {
+	for {
+		v, ok := t.(*Var)
+		if !ok || v.instance == nil {
+			return t
+		}
+		t = v.instance
+	}
+}
+
+/*
+	型変数 v が型 t の中に現れるか判定する関数.（出現検査）
+ */
+func occurs(v *Var, t Type) bool {
+	switch t := prune(t).(type) {
+	case *Var:
+		return t == v
+	case *Func:
+		for _, p := range t.Params {
+			if occurs(v, p) {
+				return true
+			}
+		}
+		return occurs(v, t.Return)
+	}
+	return false
+}
+
+/*
+	型 t に現れる, まだ決まっていない型変数を返す関数.
+ */
+func freeVars(t Type) []*Var {
+	switch t := prune(t).(type) {
+	case *Var:
+		return []*Var{t}
+	case *Func:
+		vars := []*Var{}
+		for _, p := range t.Params {
+			vars = append(vars, freeVars(p)...)
+		}
+		return append(vars, freeVars(t.Return)...)
+	}
+	return nil
+}
+
+/*
+	型 t の中の型変数を, mapping に従って置き換えた型を返す関数.
+ */
+func substitute(t Type, mapping map[*Var]Type) Type {
+	switch t := prune(t).(type) {
+	case *Var:
+		if s, ok := mapping[t]; ok {
+			return s
+		}
+		return t
+	case *Func:
+		params := []Type{}
+		for _, p := range t.Params {
+			params = append(params, substitute(p, mapping))
+		}
+		return &Func{
+			Params:   params,
+			Names:    t.Names,
+			Required: t.Required,
+			Variadic: t.Variadic,
+			Return:   substitute(t.Return, mapping),
+		}
+	default:
+		return t
+	}
+}